@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"go/token"
+	"os"
+
+	"github.com/luhring/funky/funky/analyzers/native"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/profile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var effectsFormat string
+
+// effectsCmd lists the effects of every exported function and method.
+var effectsCmd = &cobra.Command{
+	Use:   "effects [packages]",
+	Short: "List what each exported function does beyond returning values",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("must provide at least one directory or package pattern (e.g. ./...) to analyze")
+		}
+
+		cmd.SilenceUsage = true
+
+		c, err := config.FromViper(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		pkgs, err := native.Load(token.NewFileSet(), args...)
+		if err != nil {
+			return err
+		}
+
+		graph := effect.BuildGraph(pkgs, config.Catalog(c))
+
+		var exported []profile.Profile
+
+		for _, p := range profile.OfPackages(pkgs, graph) {
+			if p.Exported {
+				exported = append(exported, p)
+			}
+		}
+
+		return profile.Write(os.Stdout, exported, profile.Format(effectsFormat))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(effectsCmd)
+
+	effectsCmd.Flags().StringVar(&effectsFormat, "format", string(profile.Table), "output format: table, markdown or json")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"go/token"
+	"os"
+
+	"github.com/luhring/funky/funky/analyzers/native"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/packageinit"
+	"github.com/luhring/funky/funky/profile"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var initOrderFormat string
+
+// initOrderCmd lists the steps of package initialization in the order they run.
+var initOrderCmd = &cobra.Command{
+	Use:   "init-order [packages]",
+	Short: "List package variable initializers and init functions in the order they run",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("must provide at least one directory or package pattern (e.g. ./...) to analyze")
+		}
+
+		cmd.SilenceUsage = true
+
+		c, err := config.FromViper(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		pkgs, err := native.Load(token.NewFileSet(), args...)
+		if err != nil {
+			return err
+		}
+
+		catalog := config.Catalog(c)
+		steps := packageinit.Order(pkgs, catalog, effect.BuildGraph(pkgs, catalog))
+
+		return packageinit.Write(os.Stdout, steps, profile.Format(initOrderFormat))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initOrderCmd)
+
+	initOrderCmd.Flags().StringVar(&initOrderFormat, "format", string(profile.Table), "output format: table, markdown or json")
+}
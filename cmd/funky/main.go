@@ -28,6 +28,7 @@ import (
 	"os"
 
 	"github.com/luhring/funky/funky/analyzers/native"
+	"github.com/luhring/funky/funky/config"
 	"github.com/spf13/cobra"
 
 	homedir "github.com/mitchellh/go-homedir"
@@ -40,14 +41,23 @@ var cfgFile string
 var rootCmd = &cobra.Command{
 	Use:   "funky",
 	Short: "Go linter for functional programming",
+	// Package patterns are passed as arguments, so they mustn't be mistaken
+	// for unknown subcommands.
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return errors.New("must provide a single argument for the directory to analyze")
+		if len(args) == 0 {
+			return errors.New("must provide at least one directory or package pattern (e.g. ./...) to analyze")
 		}
 
-		directory := args[0]
+		// From here on, errors are about the analysis rather than how funky was invoked.
+		cmd.SilenceUsage = true
 
-		err := native.AnalyzeDirectory(directory)
+		c, err := config.FromViper(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		err = native.Analyze(args, c)
 		if err != nil {
 			return err
 		}
@@ -95,9 +105,10 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. This is reported on stderr so
+	// that it doesn't mix with machine-readable output.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"go/token"
+	"os"
+
+	"github.com/luhring/funky/funky/analyzers/native"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/profile"
+	"github.com/luhring/funky/funky/stats"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	statsFormat   string
+	statsBaseline string
+)
+
+// statsCmd reports the share of functions in each package that are pure and mutation-free.
+var statsCmd = &cobra.Command{
+	Use:   "stats [packages]",
+	Short: "Report the percentage of pure, mutation-free functions in each package",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("must provide at least one directory or package pattern (e.g. ./...) to analyze")
+		}
+
+		cmd.SilenceUsage = true
+
+		c, err := config.FromViper(viper.GetViper())
+		if err != nil {
+			return err
+		}
+
+		pkgs, err := native.Load(token.NewFileSet(), args...)
+		if err != nil {
+			return err
+		}
+
+		report := stats.Compute(pkgs, effect.BuildGraph(pkgs, config.Catalog(c)), c)
+
+		if statsBaseline != "" {
+			baseline, err := stats.Load(statsBaseline)
+			if err != nil {
+				return err
+			}
+
+			report = stats.Compare(baseline, report)
+		}
+
+		return stats.Write(os.Stdout, report, profile.Format(statsFormat))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsFormat, "format", string(profile.Table), "output format: table, markdown or json")
+	statsCmd.Flags().StringVar(&statsBaseline, "baseline", "", "a report previously written with --format json, to show changes against")
+}
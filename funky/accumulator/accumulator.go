@@ -0,0 +1,475 @@
+package accumulator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+)
+
+var Type finding.Type = "accumulator-loop"
+
+// Enforce that Accumulation implements the Finding and Aggregate types
+var (
+	_ finding.Finding   = (*Accumulation)(nil)
+	_ finding.Aggregate = (*Accumulation)(nil)
+)
+
+// Shape is the functional transformation that an accumulator loop is equivalent to.
+type Shape string
+
+const (
+	Map     Shape = "map"
+	Filter  Shape = "filter"
+	Reduce  Shape = "reduce"
+	GroupBy Shape = "group-by"
+)
+
+// Accumulation describes a range loop whose only job is to build up a single
+// value (the accumulator) from the elements being ranged over.
+type Accumulation struct {
+	loop        *ast.RangeStmt
+	update      ast.Stmt
+	shape       Shape
+	accumulator ast.Expr
+
+	// element is the value contributed by each iteration (for map, filter and
+	// group-by loops) or the operand combined into the accumulator (for reduce loops).
+	element ast.Expr
+
+	// condition is the predicate of a filter loop.
+	condition ast.Expr
+
+	// key is the grouping key of a group-by loop.
+	key ast.Expr
+
+	// operator describes how a reduce loop combines values, e.g. "+=".
+	operator string
+
+	helperPackage string
+}
+
+func (a Accumulation) Message(fset *token.FileSet) string {
+	message := fmt.Sprintf("loop over %s is a %s into %q: %s", render(a.loop.X, fset), a.shape, render(a.accumulator, fset), a.description(fset))
+
+	if rewrite := a.rewrite(fset); rewrite != "" {
+		message += fmt.Sprintf("; consider: %s", rewrite)
+	}
+
+	return message
+}
+
+func (a Accumulation) Type() finding.Type {
+	return Type
+}
+
+func (a Accumulation) Node() ast.Node {
+	return a.loop
+}
+
+func (a Accumulation) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(a.loop.Pos()).String())
+}
+
+func (a Accumulation) String() string {
+	return fmt.Sprintf("%s loop", a.shape)
+}
+
+func (a Accumulation) Subsumes() []ast.Node {
+	return []ast.Node{a.update}
+}
+
+// SubsumedTypes returns the mutation types, since the update is otherwise
+// reported as a mutation of the accumulator.
+func (a Accumulation) SubsumedTypes() []finding.Type {
+	return mutation.Types
+}
+
+// Shape returns the functional transformation the loop is equivalent to.
+func (a Accumulation) Shape() Shape {
+	return a.shape
+}
+
+func (a Accumulation) description(fset *token.FileSet) string {
+	switch a.shape {
+	case Map:
+		return fmt.Sprintf("each element is transformed into %s", render(a.element, fset))
+
+	case Filter:
+		return fmt.Sprintf("%s is kept where %s", render(a.element, fset), render(a.condition, fset))
+
+	case Reduce:
+		return fmt.Sprintf("elements are combined by %s", funkyAST.Render(a.update, fset))
+
+	case GroupBy:
+		return fmt.Sprintf("%s is grouped by %s", render(a.element, fset), render(a.key, fset))
+	}
+
+	return ""
+}
+
+// rewrite suggests an equivalent expression using the configured helper
+// package. It only does so when the loop's logic is already available as a
+// function value (e.g. `f(v)` rather than `v * 2`), since rendering a
+// function literal would require type information.
+func (a Accumulation) rewrite(fset *token.FileSet) string {
+	if a.helperPackage == "" {
+		return ""
+	}
+
+	helper := path.Base(a.helperPackage)
+	value := funkyAST.IdentFromExpr(a.loop.Value)
+
+	if value == nil || !isBlankOrNil(a.loop.Key) {
+		return ""
+	}
+
+	accumulator := render(a.accumulator, fset)
+	collection := render(a.loop.X, fset)
+
+	switch a.shape {
+	case Map:
+		if f := unaryCallee(a.element, value.Name); f != nil {
+			return fmt.Sprintf("%s = append(%s, %s.Map(%s, %s)...)", accumulator, accumulator, helper, collection, render(f, fset))
+		}
+
+	case Filter:
+		if ident := funkyAST.IdentFromExpr(a.element); ident == nil || ident.Name != value.Name {
+			return ""
+		}
+
+		if p := unaryCallee(a.condition, value.Name); p != nil {
+			return fmt.Sprintf("%s = append(%s, %s.Filter(%s, %s)...)", accumulator, accumulator, helper, collection, render(p, fset))
+		}
+
+	case Reduce:
+		if a.operator != "=" {
+			return ""
+		}
+
+		if f := binaryCallee(a.element, a.accumulator, value.Name); f != nil {
+			return fmt.Sprintf("%s = %s.Reduce(%s, %s, %s)", accumulator, helper, collection, accumulator, render(f, fset))
+		}
+	}
+
+	return ""
+}
+
+func FindInFiles(files []*ast.File, c config.Config) []Accumulation {
+	var result []Accumulation
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if loop, ok := node.(*ast.RangeStmt); ok {
+				if a, ok := accumulation(loop); ok {
+					a.helperPackage = c.Accumulators.HelperPackage
+					result = append(result, a)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(accumulations []Accumulation) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, a := range accumulations {
+		findings = append(findings, a)
+	}
+
+	return findings
+}
+
+func accumulation(loop *ast.RangeStmt) (Accumulation, bool) {
+	if loop.Body == nil || len(loop.Body.List) != 1 {
+		return Accumulation{}, false
+	}
+
+	stmt := loop.Body.List[0]
+
+	for _, match := range []func(*ast.RangeStmt, ast.Stmt) (Accumulation, bool){
+		mapLoop,
+		filterLoop,
+		groupByLoop,
+		reduceLoop,
+	} {
+		if a, ok := match(loop, stmt); ok {
+			if isLoopVariable(loop, a.accumulator) {
+				return Accumulation{}, false
+			}
+
+			return a, true
+		}
+	}
+
+	return Accumulation{}, false
+}
+
+// mapLoop matches `out = append(out, f(v))`. Appending the loop variable
+// itself, as in `out = append(out, v)`, copies the elements rather than
+// transforming them, so it isn't a map.
+func mapLoop(loop *ast.RangeStmt, stmt ast.Stmt) (Accumulation, bool) {
+	assignStmt, element, ok := selfAppend(stmt)
+	if !ok || isLoopVariable(loop, element) {
+		return Accumulation{}, false
+	}
+
+	return Accumulation{
+		loop:        loop,
+		update:      assignStmt,
+		shape:       Map,
+		accumulator: assignStmt.Lhs[0],
+		element:     element,
+	}, true
+}
+
+// filterLoop matches `if p(v) { out = append(out, v) }`.
+func filterLoop(loop *ast.RangeStmt, stmt ast.Stmt) (Accumulation, bool) {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil || ifStmt.Else != nil || len(ifStmt.Body.List) != 1 {
+		return Accumulation{}, false
+	}
+
+	assignStmt, element, ok := selfAppend(ifStmt.Body.List[0])
+	if !ok || references(ifStmt.Cond, assignStmt.Lhs[0]) {
+		return Accumulation{}, false
+	}
+
+	return Accumulation{
+		loop:        loop,
+		update:      assignStmt,
+		shape:       Filter,
+		accumulator: assignStmt.Lhs[0],
+		element:     element,
+		condition:   ifStmt.Cond,
+	}, true
+}
+
+// groupByLoop matches `m[k(v)] = append(m[k(v)], v)`.
+func groupByLoop(loop *ast.RangeStmt, stmt ast.Stmt) (Accumulation, bool) {
+	assignStmt, ok := stmt.(*ast.AssignStmt)
+	if !ok || assignStmt.Tok != token.ASSIGN || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return Accumulation{}, false
+	}
+
+	indexExpr, ok := assignStmt.Lhs[0].(*ast.IndexExpr)
+	if !ok || funkyAST.IdentFromExpr(indexExpr.X) == nil {
+		return Accumulation{}, false
+	}
+
+	args, ok := appendArgs(assignStmt.Rhs[0])
+	if !ok || !sameExpr(args[0], indexExpr) || references(args[1], indexExpr.X) {
+		return Accumulation{}, false
+	}
+
+	return Accumulation{
+		loop:        loop,
+		update:      assignStmt,
+		shape:       GroupBy,
+		accumulator: indexExpr.X,
+		element:     args[1],
+		key:         indexExpr.Index,
+	}, true
+}
+
+// reduceLoop matches `acc += v`, `acc = acc + v` and `acc = f(acc, v)`.
+func reduceLoop(loop *ast.RangeStmt, stmt ast.Stmt) (Accumulation, bool) {
+	assignStmt, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return Accumulation{}, false
+	}
+
+	accumulator := funkyAST.IdentFromExpr(assignStmt.Lhs[0])
+	if accumulator == nil || funkyAST.BlankIdentifier(accumulator) {
+		return Accumulation{}, false
+	}
+
+	rhs := assignStmt.Rhs[0]
+
+	result := Accumulation{
+		loop:        loop,
+		update:      assignStmt,
+		shape:       Reduce,
+		accumulator: accumulator,
+	}
+
+	switch assignStmt.Tok {
+	case token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN, token.QUO_ASSIGN,
+		token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN:
+		if references(rhs, accumulator) {
+			return Accumulation{}, false
+		}
+
+		result.operator = assignStmt.Tok.String()
+		result.element = rhs
+
+		return result, true
+
+	case token.ASSIGN:
+		switch r := rhs.(type) {
+		case *ast.BinaryExpr:
+			if !sameExpr(r.X, accumulator) || references(r.Y, accumulator) {
+				return Accumulation{}, false
+			}
+
+			result.operator = r.Op.String()
+			result.element = r.Y
+
+			return result, true
+
+		case *ast.CallExpr:
+			if len(r.Args) == 0 || !sameExpr(r.Args[0], accumulator) || r.Ellipsis.IsValid() {
+				return Accumulation{}, false
+			}
+
+			// Appending to the accumulator collects elements rather than
+			// combining them, so an append that isn't a map, like one of the
+			// unchanged loop variable, isn't a reduce either.
+			if ident := funkyAST.IdentFromExpr(r.Fun); ident != nil && ident.Name == "append" {
+				return Accumulation{}, false
+			}
+
+			for _, arg := range r.Args[1:] {
+				if references(arg, accumulator) {
+					return Accumulation{}, false
+				}
+			}
+
+			result.operator = "="
+			result.element = r
+
+			return result, true
+		}
+	}
+
+	return Accumulation{}, false
+}
+
+// selfAppend matches `out = append(out, e)` and returns the appended element.
+func selfAppend(stmt ast.Stmt) (*ast.AssignStmt, ast.Expr, bool) {
+	assignStmt, ok := stmt.(*ast.AssignStmt)
+	if !ok || assignStmt.Tok != token.ASSIGN || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return nil, nil, false
+	}
+
+	accumulator := funkyAST.IdentFromExpr(assignStmt.Lhs[0])
+	if accumulator == nil || funkyAST.BlankIdentifier(accumulator) {
+		return nil, nil, false
+	}
+
+	args, ok := appendArgs(assignStmt.Rhs[0])
+	if !ok || !sameExpr(args[0], accumulator) || references(args[1], accumulator) {
+		return nil, nil, false
+	}
+
+	return assignStmt, args[1], true
+}
+
+// appendArgs matches a call to append with exactly one element being appended.
+func appendArgs(expr ast.Expr) ([]ast.Expr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+
+	if ident := funkyAST.IdentFromExpr(call.Fun); ident == nil || ident.Name != "append" {
+		return nil, false
+	}
+
+	return call.Args, true
+}
+
+// unaryCallee returns f for an expression of the form `f(name)`.
+func unaryCallee(expr ast.Expr, name string) ast.Expr {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return nil
+	}
+
+	if arg := funkyAST.IdentFromExpr(call.Args[0]); arg == nil || arg.Name != name {
+		return nil
+	}
+
+	switch call.Fun.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return call.Fun
+	}
+
+	return nil
+}
+
+// binaryCallee returns the function called by expr when it's a call with
+// exactly two arguments, the accumulator and then the element, as a reducer
+// passed to Reduce would be called.
+func binaryCallee(expr ast.Expr, accumulator ast.Expr, name string) ast.Expr {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 || call.Ellipsis.IsValid() {
+		return nil
+	}
+
+	if !sameExpr(call.Args[0], accumulator) {
+		return nil
+	}
+
+	if arg := funkyAST.IdentFromExpr(call.Args[1]); arg == nil || arg.Name != name {
+		return nil
+	}
+
+	switch call.Fun.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return call.Fun
+	}
+
+	return nil
+}
+
+func isLoopVariable(loop *ast.RangeStmt, expr ast.Expr) bool {
+	return sameExpr(loop.Key, expr) || sameExpr(loop.Value, expr)
+}
+
+func isBlankOrNil(expr ast.Expr) bool {
+	return expr == nil || funkyAST.BlankIdentifier(funkyAST.IdentFromExpr(expr))
+}
+
+// references reports whether expr mentions the root variable of target.
+func references(expr ast.Expr, target ast.Expr) bool {
+	root := funkyAST.IdentFromExpr(target)
+	if root == nil {
+		return false
+	}
+
+	found := false
+
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == root.Name {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// sameExpr compares two expressions syntactically.
+func sameExpr(a, b ast.Expr) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	return types.ExprString(a) == types.ExprString(b)
+}
+
+func render(expr ast.Expr, fset *token.FileSet) string {
+	return funkyAST.Render(expr, fset)
+}
@@ -0,0 +1,83 @@
+package accumulator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableAccumulation struct {
+		location finding.Location
+		shape    Shape
+		message  string
+	}
+
+	expected := []testableAccumulation{
+		{
+			location: "testdata/loops/main.go:7:2",
+			shape:    Map,
+			message:  `loop over names is a map into "upper": each element is transformed into strings.ToUpper(name); consider: upper = append(upper, fp.Map(names, strings.ToUpper)...)`,
+		},
+		{
+			location: "testdata/loops/main.go:15:2",
+			shape:    Filter,
+			message:  `loop over names is a filter into "long": name is kept where isLong(name); consider: long = append(long, fp.Filter(names, isLong)...)`,
+		},
+		{
+			location: "testdata/loops/main.go:25:2",
+			shape:    Reduce,
+			message:  `loop over numbers is a reduce into "sum": elements are combined by sum += n`,
+		},
+		{
+			location: "testdata/loops/main.go:33:2",
+			shape:    Reduce,
+			message:  `loop over numbers is a reduce into "largest": elements are combined by largest = max(largest, n); consider: largest = fp.Reduce(numbers, largest, max)`,
+		},
+		{
+			location: "testdata/loops/main.go:41:2",
+			shape:    GroupBy,
+			message:  `loop over names is a group-by into "byLength": name is grouped by len(name)`,
+		},
+		{
+			location: "testdata/loops/main.go:74:2",
+			shape:    Reduce,
+			message:  `loop over numbers is a reduce into "total": elements are combined by total = scale(total, n, 2)`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/loops/main.go", nil, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("unable to load Go source test fixture: %v", err)
+	}
+
+	c := config.Default()
+	c.Accumulators.HelperPackage = "example.com/fp"
+
+	accumulations := FindInFiles([]*ast.File{file}, c)
+
+	if len(accumulations) != len(expected) {
+		for _, a := range accumulations {
+			t.Log(a.Location(fset), a.Message(fset))
+		}
+
+		t.Fatalf("expected %d accumulator loops, but found %d", len(expected), len(accumulations))
+	}
+
+	for i, a := range accumulations {
+		actual := testableAccumulation{
+			location: a.Location(fset),
+			shape:    a.Shape(),
+			message:  a.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected:\n%+v\nbut found:\n%+v", expected[i], actual)
+		}
+	}
+}
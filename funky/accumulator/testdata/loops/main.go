@@ -0,0 +1,115 @@
+package main
+
+import "strings"
+
+func mapLoop(names []string) []string {
+	var upper []string
+	for _, name := range names { // map
+		upper = append(upper, strings.ToUpper(name))
+	}
+	return upper
+}
+
+func filterLoop(names []string) []string {
+	var long []string
+	for _, name := range names { // filter
+		if isLong(name) {
+			long = append(long, name)
+		}
+	}
+	return long
+}
+
+func reduceLoop(numbers []int) int {
+	sum := 0
+	for _, n := range numbers { // reduce
+		sum += n
+	}
+	return sum
+}
+
+func reduceWithFunction(numbers []int) int {
+	largest := 0
+	for _, n := range numbers { // reduce
+		largest = max(largest, n)
+	}
+	return largest
+}
+
+func groupByLoop(names []string) map[int][]string {
+	byLength := make(map[int][]string)
+	for _, name := range names { // group-by
+		byLength[len(name)] = append(byLength[len(name)], name)
+	}
+	return byLength
+}
+
+func notAnAccumulator(names []string) {
+	var last string
+	for _, name := range names { // not an accumulator: the previous value is discarded
+		last = name
+	}
+	print(last)
+}
+
+func moreThanOneStatement(names []string) []string {
+	var out []string
+	for _, name := range names { // not an accumulator: the body does more than accumulate
+		print(name)
+		out = append(out, name)
+	}
+	return out
+}
+
+func reduceWithSwappedArguments(numbers []int) int {
+	total := 0
+	for _, n := range numbers { // not reported: the accumulator has to be the first argument
+		total = combine(n, total)
+	}
+	return total
+}
+
+func reduceWithExtraArgument(numbers []int) int {
+	total := 0
+	for _, n := range numbers { // reduce, but the function takes a third argument
+		total = scale(total, n, 2)
+	}
+	return total
+}
+
+func identityLoop(names []string) []string {
+	var copied []string
+	for _, name := range names { // not reported: the elements are copied unchanged
+		copied = append(copied, name)
+	}
+	return copied
+}
+
+func keysLoop(lengths map[string]int) []string {
+	var names []string
+	for name := range lengths { // not reported: the keys are collected unchanged
+		names = append(names, name)
+	}
+	return names
+}
+
+func isLong(s string) bool {
+	return len(s) > 10
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func combine(n, total int) int {
+	return total + n
+}
+
+func scale(total, n, factor int) int {
+	return total + n*factor
+}
+
+func main() {}
@@ -0,0 +1,253 @@
+package alias
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+)
+
+var Type finding.Type = "aliased-state"
+
+// Enforce that AliasedState implements the Finding type
+var _ finding.Finding = (*AliasedState)(nil)
+
+// AliasedState describes an exported function or method that shares a slice
+// or map with its caller instead of copying it: either by returning one of
+// its receiver's fields, or by storing a parameter in a field. Either way, the
+// caller can go on to modify state that the struct considers its own.
+type AliasedState struct {
+	node ast.Node
+	decl *ast.FuncDecl
+
+	field string
+
+	// param is set when a parameter is being stored, and is empty when a
+	// field is being returned.
+	param string
+
+	// kind is "slice" or "map".
+	kind string
+}
+
+func (a AliasedState) Message(fset *token.FileSet) string {
+	name := funcName(a.decl, fset)
+
+	if a.param != "" {
+		return fmt.Sprintf("%s stores parameter %q in field %s without copying it, so the caller can still modify the %s; store a copy instead", name, a.param, a.field, a.kind)
+	}
+
+	return fmt.Sprintf("%s returns field %s of its receiver, so callers can modify the receiver's %s; return a copy instead", name, a.field, a.kind)
+}
+
+func (a AliasedState) Type() finding.Type {
+	return Type
+}
+
+func (a AliasedState) Node() ast.Node {
+	return a.node
+}
+
+func (a AliasedState) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(a.node.Pos()).String())
+}
+
+func (a AliasedState) String() string {
+	return fmt.Sprintf("aliased %s field %s", a.kind, a.field)
+}
+
+func FindInFiles(files []*ast.File, info *types.Info) []AliasedState {
+	if info == nil {
+		return nil
+	}
+
+	var result []AliasedState
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil || !funcDecl.Name.IsExported() {
+				continue
+			}
+
+			result = append(result, findInFuncDecl(funcDecl, info)...)
+		}
+	}
+
+	return result
+}
+
+func Findings(aliases []AliasedState) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, a := range aliases {
+		findings = append(findings, a)
+	}
+
+	return findings
+}
+
+func findInFuncDecl(decl *ast.FuncDecl, info *types.Info) []AliasedState {
+	receiver := receiverOf(decl, info)
+	params := paramsOf(decl, info)
+
+	var result []AliasedState
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			// Closures returned or stored from here are a separate concern.
+			return false
+
+		case *ast.ReturnStmt:
+			for _, expr := range n.Results {
+				if field, kind, ok := receiverField(expr, receiver, info); ok {
+					result = append(result, AliasedState{node: expr, decl: decl, field: field, kind: kind})
+				}
+			}
+
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+
+			for i, lhs := range n.Lhs {
+				selector, ok := ast.Unparen(lhs).(*ast.SelectorExpr)
+				if !ok || !isField(selector, info) {
+					continue
+				}
+
+				if param, kind, ok := referenceParam(n.Rhs[i], params, info); ok {
+					result = append(result, AliasedState{node: n, decl: decl, field: selector.Sel.Name, param: param, kind: kind})
+				}
+			}
+
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok || !isStructLiteral(n, info) {
+					continue
+				}
+
+				if param, kind, ok := referenceParam(kv.Value, params, info); ok {
+					result = append(result, AliasedState{node: kv, decl: decl, field: key.Name, param: param, kind: kind})
+				}
+			}
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// receiverField matches a field of the receiver with a slice or map type, or a
+// slice of such a field, e.g. `s.items` or `s.items[1:]`.
+func receiverField(expr ast.Expr, receiver types.Object, info *types.Info) (string, string, bool) {
+	if receiver == nil {
+		return "", "", false
+	}
+
+	expr = ast.Unparen(expr)
+	if slice, ok := expr.(*ast.SliceExpr); ok {
+		expr = ast.Unparen(slice.X)
+	}
+
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok || !isField(selector, info) {
+		return "", "", false
+	}
+
+	root := funkyAST.SelectorIdentFromExpr(selector)
+	if root == nil || info.ObjectOf(root) != receiver {
+		return "", "", false
+	}
+
+	kind, ok := referenceKind(info.TypeOf(selector))
+	return selector.Sel.Name, kind, ok
+}
+
+// referenceParam matches a parameter with a slice or map type.
+func referenceParam(expr ast.Expr, params map[types.Object]struct{}, info *types.Info) (string, string, bool) {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+
+	if _, isParam := params[info.ObjectOf(ident)]; !isParam {
+		return "", "", false
+	}
+
+	kind, ok := referenceKind(info.TypeOf(ident))
+	return ident.Name, kind, ok
+}
+
+func referenceKind(t types.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	switch t.Underlying().(type) {
+	case *types.Slice:
+		return "slice", true
+	case *types.Map:
+		return "map", true
+	}
+
+	return "", false
+}
+
+func isField(selector *ast.SelectorExpr, info *types.Info) bool {
+	selection := info.Selections[selector]
+	return selection != nil && selection.Kind() == types.FieldVal
+}
+
+func isStructLiteral(lit *ast.CompositeLit, info *types.Info) bool {
+	t := info.TypeOf(lit)
+	if t == nil {
+		return false
+	}
+
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+func receiverOf(decl *ast.FuncDecl, info *types.Info) types.Object {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 || len(decl.Recv.List[0].Names) == 0 {
+		return nil
+	}
+
+	return info.Defs[decl.Recv.List[0].Names[0]]
+}
+
+func paramsOf(decl *ast.FuncDecl, info *types.Info) map[types.Object]struct{} {
+	result := make(map[types.Object]struct{})
+
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			if obj := info.Defs[name]; obj != nil {
+				result[obj] = struct{}{}
+			}
+		}
+	}
+
+	return result
+}
+
+// funcName returns the name of a function, or of a method along with its
+// receiver type, e.g. "(*Store).Items".
+func funcName(decl *ast.FuncDecl, fset *token.FileSet) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+
+	return fmt.Sprintf("(%s).%s", funkyAST.Render(decl.Recv.List[0].Type, fset), decl.Name.Name)
+}
@@ -0,0 +1,51 @@
+package alias
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableAliasedState struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableAliasedState{
+		{"testdata/store/main.go:16:16", `NewStore stores parameter "items" in field items without copying it, so the caller can still modify the slice; store a copy instead`},
+		{"testdata/store/main.go:24:9", "(*Store).Items returns field items of its receiver, so callers can modify the receiver's slice; return a copy instead"},
+		{"testdata/store/main.go:32:9", "(*Store).Rest returns field items of its receiver, so callers can modify the receiver's slice; return a copy instead"},
+		{"testdata/store/main.go:36:9", "(*Store).Counts returns field counts of its receiver, so callers can modify the receiver's map; return a copy instead"},
+		{"testdata/store/main.go:48:9", "(*Store).Tags returns field tags of its receiver, so callers can modify the receiver's slice; return a copy instead"},
+		{"testdata/store/main.go:52:2", `(*Store).SetItems stores parameter "items" in field items without copying it, so the caller can still modify the slice; store a copy instead`},
+		{"testdata/store/main.go:64:2", `Merge stores parameter "counts" in field counts without copying it, so the caller can still modify the map; store a copy instead`},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "store/main.go")
+
+	aliases := FindInFiles([]*ast.File{file}, info)
+
+	if len(aliases) != len(expected) {
+		for _, a := range aliases {
+			t.Log(a.Location(fset), a.Message(fset))
+		}
+
+		t.Fatalf("expected %d aliased states, but found %d", len(expected), len(aliases))
+	}
+
+	for i, a := range aliases {
+		actual := testableAliasedState{
+			location: a.Location(fset),
+			message:  a.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
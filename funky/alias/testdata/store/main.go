@@ -0,0 +1,70 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+type Store struct {
+	items  []string
+	counts map[string]int
+	name   string
+	inner  struct{ tags []string }
+}
+
+func NewStore(items []string) *Store {
+	return &Store{items: items}
+}
+
+func NewStoreCopy(items []string) *Store {
+	return &Store{items: slices.Clone(items)}
+}
+
+func (s *Store) Items() []string {
+	return s.items
+}
+
+func (s *Store) ItemsCopy() []string {
+	return slices.Clone(s.items)
+}
+
+func (s *Store) Rest() []string {
+	return (s.items[1:])
+}
+
+func (s *Store) Counts() map[string]int {
+	return s.counts
+}
+
+func (s *Store) CountsCopy() map[string]int {
+	return maps.Clone(s.counts)
+}
+
+func (s *Store) Name() string {
+	return s.name
+}
+
+func (s *Store) Tags() []string {
+	return s.inner.tags
+}
+
+func (s *Store) SetItems(items []string) {
+	s.items = items
+}
+
+func (s *Store) SetItemsCopy(items []string) {
+	s.items = append([]string(nil), items...)
+}
+
+func (s *Store) items2() []string {
+	return s.items
+}
+
+func Merge(s *Store, counts map[string]int) {
+	s.counts = counts
+}
+
+func main() {
+	s := NewStore([]string{"a", "b"})
+	_ = s.items2()
+}
@@ -2,43 +2,57 @@ package native
 
 import (
 	"fmt"
-	"go/ast"
-	"go/parser"
 	"go/token"
 
-	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
 	"github.com/luhring/funky/funky/finding"
-	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/rules"
+	"golang.org/x/tools/go/packages"
 )
 
-func AnalyzeDirectory(path string) error {
+// Analyze loads the packages matching the patterns (e.g. "./..." or
+// "./internal/domain") and reports the findings of every rule.
+func Analyze(patterns []string, c config.Config) error {
 	fset := token.NewFileSet()
-	packages, err := parser.ParseDir(fset, path, nil, parser.AllErrors)
+
+	pkgs, err := Load(fset, patterns...)
 	if err != nil {
 		return err
 	}
 
-	for _, f := range findings(packages) {
+	findings := rules.FindingsInPackages(pkgs, c)
+
+	for _, f := range findings {
 		fmt.Println(finding.Report(f, fset))
 	}
 
+	if enforced := rules.Enforced(findings, c); len(enforced) > 0 {
+		return fmt.Errorf("found %d violation(s) of enforced rules", len(enforced))
+	}
+
 	return nil
 }
 
-func findings(packages map[string]*ast.Package) []finding.Finding {
-	var findings []finding.Finding
-
-	for _, packageNode := range packages {
-		if packageNode == nil {
-			continue
-		}
+// Load parses and type checks the packages matching the patterns. Type errors
+// are ignored, the same way the stdlib analyzer runs despite errors, so that
+// rules still see the parts of a package that do check. A package that can't
+// be loaded at all results in an error.
+func Load(fset *token.FileSet, patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Fset: fset,
+	}
 
-		packageFiles := funkyAST.SortedFilesFromPackage(packageNode)
-		mutations := mutation.FindInFiles(packageFiles)
-		mutationFindings := mutation.Findings(mutations)
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
 
-		findings = append(findings, mutationFindings...)
+	for _, pkg := range pkgs {
+		if len(pkg.Syntax) == 0 && len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("unable to load package %q: %v", pkg.PkgPath, pkg.Errors[0])
+		}
 	}
 
-	return findings
+	return pkgs, nil
 }
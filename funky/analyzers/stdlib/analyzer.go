@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"go/token"
 
+	"github.com/luhring/funky/funky/config"
 	"github.com/luhring/funky/funky/finding"
-	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/rules"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
 )
 
 var Analyzer = analysis.Analyzer{
@@ -23,11 +25,60 @@ var Analyzer = analysis.Analyzer{
 	FactTypes:        nil,
 }
 
+// configFile loads the config when the flag is set, so that it's loaded once
+// rather than on every package pass.
+var configFile = configFlag{config: config.Default()}
+
+func init() {
+	Analyzer.Flags.Var(&configFile, "config", "path to a funky config file")
+}
+
+type configFlag struct {
+	path   string
+	config config.Config
+}
+
+func (f *configFlag) String() string {
+	return f.path
+}
+
+func (f *configFlag) Set(path string) error {
+	c, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	f.path = path
+	f.config = c
+
+	return nil
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
-	mutations := mutation.FindInFiles(pass.Files)
+	c := configFile.config
+
+	// The call graph is built from the package under analysis alone: its
+	// dependencies are added to the program without function bodies, so no
+	// pass builds the graph of another package. Since only this package's
+	// function bodies are available, effect boundaries are checked as far as
+	// the catalog and this package's own calls allow. The pass's type
+	// information records the instantiations of generic functions and types,
+	// which the call graph needs to follow calls into generic code. The
+	// analyzer runs despite type errors, so they're passed along too, since the
+	// call graph can't be built from ill-typed code.
+	pkg := &packages.Package{
+		PkgPath:    pass.Pkg.Path(),
+		Fset:       pass.Fset,
+		Types:      pass.Pkg,
+		Syntax:     pass.Files,
+		TypesInfo:  pass.TypesInfo,
+		TypeErrors: pass.TypeErrors,
+	}
+
+	findings := rules.FindingsInPackages([]*packages.Package{pkg}, c)
 
-	for _, m := range mutations {
-		diagnostic := diagnostic(m, pass.Fset)
+	for _, f := range findings {
+		diagnostic := diagnostic(f, pass.Fset)
 
 		if report := pass.Report; report != nil {
 			report(diagnostic)
@@ -38,11 +89,17 @@ func run(pass *analysis.Pass) (interface{}, error) {
 }
 
 func diagnostic(f finding.Finding, fset *token.FileSet) analysis.Diagnostic {
+	var suggestedFixes []analysis.SuggestedFix
+	if fixer, ok := f.(finding.Fixer); ok {
+		suggestedFixes = fixer.SuggestedFixes(fset)
+	}
+
 	return analysis.Diagnostic{
-		Pos:      f.Node().Pos(),
-		End:      f.Node().End(),
-		Category: string(f.Type()),
-		Message:  message(f, fset),
+		Pos:            f.Node().Pos(),
+		End:            f.Node().End(),
+		Category:       string(f.Type()),
+		Message:        message(f, fset),
+		SuggestedFixes: suggestedFixes,
 	}
 }
 
@@ -0,0 +1,23 @@
+package stdlib
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer_typeErrors(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), &Analyzer, "illtyped")
+}
+
+func TestConfigFlag_Set(t *testing.T) {
+	var f configFlag
+
+	if err := f.Set("testdata/missing.yaml"); err == nil {
+		t.Error("expected an error loading a missing config file")
+	}
+
+	if f.String() != "" {
+		t.Errorf("expected the path to stay unset after a failed load, but got %q", f.String())
+	}
+}
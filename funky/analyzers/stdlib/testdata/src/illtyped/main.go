@@ -0,0 +1,18 @@
+// Package illtyped doesn't type-check, which the analyzer has to cope with
+// since it runs despite errors.
+package illtyped
+
+import "os"
+
+var missing = undefined()
+
+func chdir() error {
+	err := os.Chdir("/")
+	return err + 1
+}
+
+func count() int {
+	n := 0
+	n = 1 // want `"n" was assigned a new value: 1`
+	return n
+}
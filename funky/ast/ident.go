@@ -25,3 +25,24 @@ func SelectorIdentFromExpr(expr ast.Expr) *ast.Ident {
 
 	return nil
 }
+
+// RootIdentFromExpr returns the variable at the root of an expression that
+// refers to part of a value, e.g. `c` in `c.items[i].name` or `*c`.
+func RootIdentFromExpr(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return RootIdentFromExpr(e.X)
+	case *ast.IndexExpr:
+		return RootIdentFromExpr(e.X)
+	case *ast.SliceExpr:
+		return RootIdentFromExpr(e.X)
+	case *ast.StarExpr:
+		return RootIdentFromExpr(e.X)
+	case *ast.ParenExpr:
+		return RootIdentFromExpr(e.X)
+	}
+
+	return nil
+}
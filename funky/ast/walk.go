@@ -64,15 +64,15 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 		funcScope := scope.NewInsideExisting(s)
 
 		// what gets added to scope before further walking?
-		// - receiver
+		// - receiver, and the type parameters of a generic receiver type
 		if n.Recv != nil {
 			for _, field := range n.Recv.List {
-				funcScope = scope.Append(funcScope, field.Names...)
+				funcScope = scope.AppendOfKind(funcScope, scope.Receiver, field.Names...)
+				funcScope = scope.AppendOfKind(funcScope, scope.TypeParameter, receiverTypeParams(field.Type)...)
 			}
 		}
 
-		idents := declarationIdentsFromFuncType(n.Type)
-		funcScope = scope.Append(funcScope, idents...)
+		funcScope = appendFuncTypeDeclarations(funcScope, n.Type)
 
 		// Walk child notes of function -> body
 		if funcBody := n.Body; funcBody != nil {
@@ -86,6 +86,7 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 	case *ast.ForStmt:
 		// grab new scope from init stmt
 		if n.Init == nil {
+			walkForClauses(v, n, s)
 			Walk(v, n.Body, s)
 			break
 		}
@@ -98,6 +99,7 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 			s = scope.Append(s, idents...)
 		}
 
+		walkForClauses(v, n, initScope)
 		Walk(v, n.Body, initScope)
 
 	case *ast.RangeStmt:
@@ -128,6 +130,7 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 
 	case *ast.IfStmt:
 		if n.Init == nil {
+			Walk(v, n.Cond, s)
 			walkIfBlocks(v, n, s)
 			break
 		}
@@ -140,10 +143,12 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 			initScope = scope.Append(initScope, idents...)
 		}
 
+		Walk(v, n.Cond, initScope)
 		walkIfBlocks(v, n, initScope)
 
 	case *ast.SwitchStmt:
 		if n.Init == nil {
+			walkExpr(v, n.Tag, s)
 			Walk(v, n.Body, scope.NewInsideExisting(s))
 			break
 		}
@@ -156,16 +161,23 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 			initScope = scope.Append(initScope, idents...)
 		}
 
+		walkExpr(v, n.Tag, initScope)
 		Walk(v, n.Body, scope.NewInsideExisting(initScope))
 
 	case *ast.TypeSwitchStmt:
 		if n.Init != nil {
+			Walk(v, n.Init, s)
+
 			if initStmt, ok := n.Init.(*ast.AssignStmt); ok {
 				idents := declarationIdentsFromAssignStmt(initStmt, s)
 				s = scope.Append(s, idents...)
 			}
 		}
 
+		// Only the guarded expression is walked, e.g. x in `v := x.(type)`,
+		// since v is declared anew in each clause rather than assigned.
+		walkExpr(v, typeSwitchGuard(n), s)
+
 		if n.Body != nil {
 			Walk(v, n.Body, s)
 		}
@@ -173,11 +185,33 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 	case *ast.CaseClause:
 		walkStmtList(v, n.Body, scope.NewInsideExisting(s))
 
+	case *ast.SelectStmt:
+		Walk(v, n.Body, s)
+
+	case *ast.CommClause:
+		clauseScope := scope.NewInsideExisting(s)
+
+		// A receive can declare variables for the clause's body (e.g. `case v := <-ch:`)
+		if n.Comm != nil {
+			walkStmtList(v, []ast.Stmt{n.Comm}, clauseScope)
+
+			if assignStmt, ok := n.Comm.(*ast.AssignStmt); ok {
+				clauseScope = scope.Append(clauseScope, declarationIdentsFromAssignStmt(assignStmt, clauseScope)...)
+			}
+		}
+
+		walkStmtList(v, n.Body, clauseScope)
+
+	case *ast.SendStmt:
+		Walk(v, n.Chan, s)
+		Walk(v, n.Value, s)
+
+	case *ast.UnaryExpr:
+		Walk(v, n.X, s)
+
 	case *ast.FuncLit:
 		funcScope := scope.NewInsideExisting(s)
-
-		idents := declarationIdentsFromFuncType(n.Type)
-		funcScope = scope.Append(funcScope, idents...)
+		funcScope = appendFuncTypeDeclarations(funcScope, n.Type)
 
 		if funcBody := n.Body; funcBody != nil {
 			Walk(v, funcBody, funcScope)
@@ -207,6 +241,11 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 		}
 
 	case *ast.CallExpr:
+		// The function is walked along with the arguments so that the body of
+		// a function literal that's called right away, e.g. a deferred
+		// closure, is visited like the rest of the enclosing function.
+		Walk(v, n.Fun, s)
+
 		for _, arg := range n.Args {
 			Walk(v, arg, s)
 		}
@@ -214,9 +253,52 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 	case *ast.ParenExpr:
 		Walk(v, n.X, s)
 
+	case *ast.BinaryExpr:
+		Walk(v, n.X, s)
+		Walk(v, n.Y, s)
+
+	case *ast.SelectorExpr:
+		Walk(v, n.X, s)
+
+	case *ast.StarExpr:
+		Walk(v, n.X, s)
+
+	case *ast.TypeAssertExpr:
+		Walk(v, n.X, s)
+
+	case *ast.SliceExpr:
+		Walk(v, n.X, s)
+
+		for _, index := range []ast.Expr{n.Low, n.High, n.Max} {
+			walkExpr(v, index, s)
+		}
+
+	case *ast.IndexExpr:
+		Walk(v, n.X, s)
+		Walk(v, n.Index, s)
+
+	case *ast.IndexListExpr:
+		// e.g. the instantiation `Map[string, int]`
+		Walk(v, n.X, s)
+
+		for _, index := range n.Indices {
+			Walk(v, index, s)
+		}
+
 	case *ast.ExprStmt:
 		Walk(v, n.X, s)
 
+	case *ast.AssignStmt:
+		// Any identifiers the statement declares are added to scope by the
+		// enclosing statement, since they're only visible after it.
+		for _, expr := range n.Lhs {
+			Walk(v, expr, s)
+		}
+
+		for _, expr := range n.Rhs {
+			Walk(v, expr, s)
+		}
+
 	case *ast.GenDecl:
 		for _, spec := range n.Specs {
 			Walk(v, spec, s)
@@ -236,6 +318,43 @@ func Walk(v Visitor, node ast.Node, existingScope scope.Scope) {
 	v.Visit(nil, s)
 }
 
+// walkForClauses walks a for statement's condition and post statement, which
+// see the variables declared by its init statement, like its body.
+func walkForClauses(v Visitor, forStmt *ast.ForStmt, s scope.Scope) {
+	walkExpr(v, forStmt.Cond, s)
+
+	if forStmt.Post != nil {
+		Walk(v, forStmt.Post, s)
+	}
+}
+
+// typeSwitchGuard returns the expression whose type a type switch switches on.
+func typeSwitchGuard(typeSwitch *ast.TypeSwitchStmt) ast.Expr {
+	var expr ast.Expr
+
+	switch assign := typeSwitch.Assign.(type) {
+	case *ast.ExprStmt:
+		expr = assign.X
+	case *ast.AssignStmt:
+		if len(assign.Rhs) == 1 {
+			expr = assign.Rhs[0]
+		}
+	}
+
+	if typeAssert, ok := expr.(*ast.TypeAssertExpr); ok {
+		return typeAssert.X
+	}
+
+	return nil
+}
+
+// walkExpr walks an optional expression, such as a switch statement's tag.
+func walkExpr(v Visitor, expr ast.Expr, s scope.Scope) {
+	if expr != nil {
+		Walk(v, expr, s)
+	}
+}
+
 func walkIfBlocks(v Visitor, ifStmt *ast.IfStmt, s scope.Scope) {
 	if ifStmt.Body != nil {
 		Walk(v, ifStmt.Body, scope.NewInsideExisting(s))
@@ -246,32 +365,74 @@ func walkIfBlocks(v Visitor, ifStmt *ast.IfStmt, s scope.Scope) {
 	}
 }
 
-func declarationIdentsFromFuncType(funcType *ast.FuncType) []*ast.Ident {
+// appendFuncTypeDeclarations adds a function's type parameters, parameters and
+// named results to scope.
+func appendFuncTypeDeclarations(s scope.Scope, funcType *ast.FuncType) scope.Scope {
 	if funcType == nil {
-		return nil
+		return s
 	}
 
-	var idents []*ast.Ident
+	// - type parameters
+	s = scope.AppendDeclarations(s, fieldListDeclarations(funcType.TypeParams, scope.TypeParameter)...)
 
 	// - input parameters
-	if params := funcType.Params; params != nil {
-		for _, parameter := range params.List {
-			for _, parameterIdent := range parameter.Names {
-				idents = append(idents, parameterIdent)
-			}
-		}
-	}
+	s = scope.AppendDeclarations(s, fieldListDeclarations(funcType.Params, scope.Parameter)...)
 
 	// - output parameters
-	if results := funcType.Results; results != nil {
-		for _, param := range results.List {
-			idents = append(idents, param.Names...)
+	s = scope.AppendDeclarations(s, fieldListDeclarations(funcType.Results, scope.Result)...)
+
+	return s
+}
+
+// receiverTypeParams returns the type parameters named by a generic method's
+// receiver type, e.g. K and V in `func (p *Pair[K, V]) Set(...)`.
+func receiverTypeParams(recvType ast.Expr) []*ast.Ident {
+	if star, ok := recvType.(*ast.StarExpr); ok {
+		recvType = star.X
+	}
+
+	var indices []ast.Expr
+
+	switch t := ast.Unparen(recvType).(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+
+	var idents []*ast.Ident
+
+	for _, index := range indices {
+		if ident := IdentFromExpr(index); ident != nil {
+			idents = append(idents, ident)
 		}
 	}
 
 	return idents
 }
 
+func fieldListDeclarations(fields *ast.FieldList, kind scope.Kind) []scope.Declaration {
+	if fields == nil {
+		return nil
+	}
+
+	var decls []scope.Declaration
+	index := 0
+
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			decls = append(decls, scope.Declaration{Ident: name, Kind: kind, Index: index})
+			index++
+		}
+
+		if len(field.Names) == 0 {
+			index++
+		}
+	}
+
+	return decls
+}
+
 func walkStmtList(v Visitor, list []ast.Stmt, s scope.Scope) {
 	// Note: stmts can add to scope for subsequent stmts
 
@@ -289,9 +450,9 @@ func walkStmtList(v Visitor, list []ast.Stmt, s scope.Scope) {
 
 		// (e.g. `var/const foo string`)
 		case *ast.DeclStmt:
+			// The declaration itself has already been walked along with the
+			// statement.
 			if genDecl, ok := statement.Decl.(*ast.GenDecl); ok {
-				Walk(v, genDecl, s)
-
 				for _, spec := range genDecl.Specs {
 					if v, ok := spec.(*ast.ValueSpec); ok {
 						s = scope.Append(s, v.Names...)
@@ -301,14 +462,6 @@ func walkStmtList(v Visitor, list []ast.Stmt, s scope.Scope) {
 
 		// (e.g. `a, b := bar()`) <- NEED TO KNOW WHICH LHS ITEMS ARE NEW!
 		case *ast.AssignStmt:
-			for _, expr := range statement.Lhs {
-				Walk(v, expr, s)
-			}
-
-			for _, expr := range statement.Rhs {
-				Walk(v, expr, s)
-			}
-
 			s = scope.Append(s, declarationIdentsFromAssignStmt(statement, s)...)
 		}
 	}
@@ -0,0 +1,149 @@
+package ast
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/scope"
+)
+
+func TestWalk_immediatelyInvokedFuncLit(t *testing.T) {
+	const src = `package p
+
+func f() {
+	total := 0
+
+	defer func(n int) {
+		total = n
+	}(1)
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visited bool
+
+	Inspect(file, func(node ast.Node, s scope.Scope) bool {
+		if assignStmt, ok := node.(*ast.AssignStmt); ok && assignStmt.Tok == token.ASSIGN {
+			visited = true
+
+			if _, ok := scope.Lookup(s, "n"); !ok {
+				t.Error("expected the function literal's parameter to be in scope")
+			}
+
+			if _, ok := scope.LookupOuter(s, "total"); !ok {
+				t.Error("expected the enclosing function's variable to be in an outer scope")
+			}
+		}
+
+		return true
+	})
+
+	if !visited {
+		t.Error("expected the body of the function literal to be visited")
+	}
+}
+
+func TestWalk_declStmtVisitedOnce(t *testing.T) {
+	const src = `package p
+
+func f() {
+	var a, b = g(), g()
+	print(a, b)
+}
+
+func g() int { return 0 }
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visits := make(map[ast.Node]int)
+
+	Inspect(file, func(node ast.Node, _ scope.Scope) bool {
+		switch node.(type) {
+		case *ast.GenDecl, *ast.ValueSpec, *ast.CallExpr:
+			visits[node]++
+		}
+
+		return true
+	})
+
+	for node, n := range visits {
+		if n != 1 {
+			t.Errorf("expected %T at %s to be visited once, but it was visited %d times", node, fset.Position(node.Pos()), n)
+		}
+	}
+}
+
+func TestWalk_clausesVisitedOnce(t *testing.T) {
+	const src = `package p
+
+func f() {
+	if a := g(); a > g() {
+	}
+
+	for i := g(); i < g(); i = g() {
+	}
+
+	switch b := g(); g() {
+	}
+
+	switch c := g(); interface{}(c).(type) {
+	}
+
+	ch := make(chan int)
+	select {
+	case v := <-ch:
+		_ = v
+	}
+}
+
+func g() int { return 0 }
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visits := make(map[ast.Node]int)
+
+	Inspect(file, func(node ast.Node, _ scope.Scope) bool {
+		switch node.(type) {
+		case *ast.CallExpr, *ast.UnaryExpr:
+			visits[node]++
+		}
+
+		return true
+	})
+
+	calls := 0
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.CallExpr, *ast.UnaryExpr:
+			calls++
+
+			if visits[node] != 1 {
+				t.Errorf("expected %T at %s to be visited once, but it was visited %d times", node, fset.Position(node.Pos()), visits[node])
+			}
+		}
+
+		return true
+	})
+
+	if calls == 0 {
+		t.Fatal("expected the source to contain calls")
+	}
+}
@@ -0,0 +1,205 @@
+package boundary
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "effect-boundary"
+
+// Enforce that Violation implements the Finding type
+var _ finding.Finding = (*Violation)(nil)
+
+// Violation describes a place where a core package, which is configured to be
+// effect-free, calls into a function that has effects or into a shell package.
+type Violation struct {
+	// node is the call (or the reference to a variable like os.Stdout) in the core package.
+	node ast.Node
+
+	corePackage string
+
+	// name is the function or variable being used, and summary holds its
+	// effects, including the chain of calls that leads to each of them.
+	name    string
+	summary effect.Summary
+
+	// shellPackage is set when the callee is in a shell package, rather than
+	// having effects the effect catalog knows about.
+	shellPackage string
+}
+
+func (v Violation) Message(fset *token.FileSet) string {
+	reason := fmt.Sprintf("has effects: %s", v.summary)
+	if v.shellPackage != "" {
+		reason = fmt.Sprintf("is in shell package %q", v.shellPackage)
+	}
+
+	verb := "calls"
+	if _, isCall := v.node.(*ast.CallExpr); !isCall {
+		verb = "uses"
+	}
+
+	return fmt.Sprintf("core package %q must be effect-free, but %s %s, which %s", v.corePackage, verb, v.name, reason)
+}
+
+func (v Violation) Type() finding.Type {
+	return Type
+}
+
+func (v Violation) Node() ast.Node {
+	return v.node
+}
+
+func (v Violation) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(v.node.Pos()).String())
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s reaches %s", v.corePackage, v.name)
+}
+
+// FindInPackages reports every call from a core package that can reach an
+// effect, according to catalog and g, along with every direct call into a shell package.
+// Since g is built from all of the given packages, loading the whole module
+// finds paths that pass through other packages, including calls through
+// interfaces and function values.
+func FindInPackages(pkgs []*packages.Package, c config.Config, catalog effect.Catalog, g *effect.Graph) []Violation {
+	if len(c.Boundaries.Core) == 0 {
+		return nil
+	}
+
+	// loaded holds the packages whose function bodies the graph has followed.
+	loaded := make(map[*types.Package]bool)
+	for _, pkg := range pkgs {
+		loaded[pkg.Types] = true
+	}
+
+	sorted := append([]*packages.Package(nil), pkgs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PkgPath < sorted[j].PkgPath
+	})
+
+	var result []Violation
+
+	for _, pkg := range sorted {
+		if !Matches(c.Boundaries.Core, pkg.PkgPath) || pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				var v Violation
+				var ok bool
+
+				switch n := node.(type) {
+				case *ast.CallExpr:
+					v, ok = violationOfCall(n, pkg.TypesInfo, c, loaded, g)
+				case *ast.Ident:
+					v, ok = violationOfVar(n, pkg.TypesInfo, catalog)
+				}
+
+				if ok {
+					v.corePackage = pkg.PkgPath
+					result = append(result, v)
+				}
+
+				return true
+			})
+		}
+	}
+
+	return result
+}
+
+func Findings(violations []Violation) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, v := range violations {
+		findings = append(findings, v)
+	}
+
+	return findings
+}
+
+// Matches reports whether the package at pkgPath matches any of the patterns.
+// A pattern ending in "/..." matches the package and every package beneath it.
+func Matches(patterns []string, pkgPath string) bool {
+	return effect.MatchPackage(patterns, pkgPath)
+}
+
+// violationOfCall reports a call from a core package into a shell package or
+// to a function with effects. Calls to other core functions whose bodies are
+// loaded are skipped, since any effect they reach is reported inside that
+// function.
+func violationOfCall(call *ast.CallExpr, info *types.Info, c config.Config, loaded map[*types.Package]bool, g *effect.Graph) (Violation, bool) {
+	callee := typeutil.Callee(info, call)
+
+	if callee != nil && callee.Pkg() != nil {
+		if loaded[callee.Pkg()] && Matches(c.Boundaries.Core, callee.Pkg().Path()) && !isInterfaceMethod(callee) {
+			return Violation{}, false
+		}
+
+		if Matches(c.Boundaries.Shell, callee.Pkg().Path()) {
+			return Violation{node: call, name: calleeName(call, callee), shellPackage: callee.Pkg().Path()}, true
+		}
+	}
+
+	summary := g.OfCallSite(call)
+	if len(summary.Effects) == 0 {
+		return Violation{}, false
+	}
+
+	return Violation{node: call, name: calleeName(call, callee), summary: summary}, true
+}
+
+// violationOfVar reports a use of a package-level variable with effects, like
+// os.Stdout.
+func violationOfVar(ident *ast.Ident, info *types.Info, catalog effect.Catalog) (Violation, bool) {
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return Violation{}, false
+	}
+
+	effects := effect.Lookup(catalog, v)
+	if len(effects) == 0 {
+		return Violation{}, false
+	}
+
+	return Violation{node: ident, name: effect.ObjectName(v), summary: effect.Summary{Effects: effects}}, true
+}
+
+// isInterfaceMethod reports whether obj is an interface's method, which has no
+// body of its own.
+func isInterfaceMethod(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	recv := fn.Signature().Recv()
+
+	return recv != nil && types.IsInterface(recv.Type())
+}
+
+// calleeName names the function being called, mapping instantiations of
+// generic functions back to their declarations.
+func calleeName(call *ast.CallExpr, callee types.Object) string {
+	if fn, ok := callee.(*types.Func); ok {
+		return effect.ObjectName(fn.Origin())
+	}
+
+	if callee != nil {
+		return effect.ObjectName(callee)
+	}
+
+	return types.ExprString(call.Fun)
+}
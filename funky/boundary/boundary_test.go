@@ -0,0 +1,103 @@
+package boundary
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInPackages(t *testing.T) {
+	type testableViolation struct {
+		location finding.Location
+		message  string
+	}
+
+	cases := []struct {
+		name       string
+		boundaries config.Boundaries
+		expected   []testableViolation
+	}{
+		{
+			name:       "core only",
+			boundaries: config.Boundaries{Core: []string{"example.com/layers/domain/..."}},
+			expected: []testableViolation{
+				{"testdata/layers/domain/order.go:28:16", `core package "example.com/layers/domain" must be effect-free, but calls time.Now, which has effects: time`},
+				{"testdata/layers/domain/order.go:32:9", `core package "example.com/layers/domain" must be effect-free, but calls example.com/layers/store.Put, which has effects: filesystem (via example.com/layers/store.Put → example.com/layers/store.write → os.WriteFile)`},
+				{"testdata/layers/domain/order.go:40:2", `core package "example.com/layers/domain" must be effect-free, but calls println, which has effects: stderr`},
+				{"testdata/layers/domain/order.go:40:13", `core package "example.com/layers/domain" must be effect-free, but uses os.Args, which has effects: global-state`},
+				{"testdata/layers/domain/order.go:49:9", `core package "example.com/layers/domain" must be effect-free, but calls (example.com/layers/domain.Saver).Save, which has effects: filesystem (via (example.com/layers/app.disk).Save → example.com/layers/store.Put → example.com/layers/store.write → os.WriteFile)`},
+			},
+		},
+		{
+			name: "core and shell",
+			boundaries: config.Boundaries{
+				Core:  []string{"example.com/layers/domain/..."},
+				Shell: []string{"example.com/layers/store"},
+			},
+			expected: []testableViolation{
+				{"testdata/layers/domain/order.go:28:16", `core package "example.com/layers/domain" must be effect-free, but calls time.Now, which has effects: time`},
+				{"testdata/layers/domain/order.go:32:9", `core package "example.com/layers/domain" must be effect-free, but calls example.com/layers/store.Put, which is in shell package "example.com/layers/store"`},
+				{"testdata/layers/domain/order.go:40:2", `core package "example.com/layers/domain" must be effect-free, but calls println, which has effects: stderr`},
+				{"testdata/layers/domain/order.go:40:13", `core package "example.com/layers/domain" must be effect-free, but uses os.Args, which has effects: global-state`},
+				{"testdata/layers/domain/order.go:49:9", `core package "example.com/layers/domain" must be effect-free, but calls (example.com/layers/domain.Saver).Save, which has effects: filesystem (via (example.com/layers/app.disk).Save → example.com/layers/store.Put → example.com/layers/store.write → os.WriteFile)`},
+				{"testdata/layers/domain/pricing/pricing.go:16:9", `core package "example.com/layers/domain/pricing" must be effect-free, but calls example.com/layers/store.Get, which is in shell package "example.com/layers/store"`},
+			},
+		},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "layers")
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Default()
+			c.Boundaries = tc.boundaries
+
+			catalog := config.Catalog(c)
+			violations := FindInPackages(pkgs, c, catalog, effect.BuildGraph(pkgs, catalog))
+
+			if len(violations) != len(tc.expected) {
+				for _, v := range violations {
+					t.Log(fixture.RelativeLocation(t, v.Location(fset)), v.Message(fset))
+				}
+
+				t.Fatalf("expected %d violations, but found %d", len(tc.expected), len(violations))
+			}
+
+			for i, v := range violations {
+				actual := testableViolation{
+					location: fixture.RelativeLocation(t, v.Location(fset)),
+					message:  v.Message(fset),
+				}
+
+				if actual != tc.expected[i] {
+					t.Errorf("expected %+v, but found %+v", tc.expected[i], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		pattern string
+		pkgPath string
+		matches bool
+	}{
+		{"example.com/app/domain", "example.com/app/domain", true},
+		{"example.com/app/domain", "example.com/app/domain/orders", false},
+		{"example.com/app/domain/...", "example.com/app/domain", true},
+		{"example.com/app/domain/...", "example.com/app/domain/orders", true},
+		{"example.com/app/domain/...", "example.com/app/domainx", false},
+	}
+
+	for _, tc := range cases {
+		if actual := Matches([]string{tc.pattern}, tc.pkgPath); actual != tc.matches {
+			t.Errorf("expected Matches(%q, %q) to be %t", tc.pattern, tc.pkgPath, tc.matches)
+		}
+	}
+}
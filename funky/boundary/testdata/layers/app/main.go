@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/layers/domain"
+	"example.com/layers/store"
+)
+
+type disk struct{}
+
+func (disk) Save(o domain.Order) error {
+	return store.Put(o.ID, o.Items)
+}
+
+func main() {
+	o := domain.Normalize(domain.Order{ID: "a"})
+	fmt.Println(o.Total, domain.Save(o), domain.Persist(disk{}, o))
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"example.com/layers/domain/pricing"
+	"example.com/layers/store"
+)
+
+type Order struct {
+	ID    string
+	Items []string
+	Total int
+}
+
+// Pure functions are fine, including calls to other core packages.
+func Normalize(o Order) Order {
+	return Order{
+		ID:    strings.ToUpper(o.ID),
+		Items: o.Items,
+		Total: pricing.Total(o.Items),
+	}
+}
+
+func Stamp(o Order) string {
+	return o.ID + time.Now().String()
+}
+
+func Save(o Order) error {
+	return store.Put(o.ID, o.Items)
+}
+
+func Load(id string) []string {
+	return pricing.Discounted(id)
+}
+
+func Debug() {
+	println(os.Args[0])
+}
+
+type Saver interface {
+	Save(o Order) error
+}
+
+// Calls through interfaces reach whichever implementations are passed in.
+func Persist(s Saver, o Order) error {
+	return s.Save(o)
+}
@@ -0,0 +1,17 @@
+package pricing
+
+import (
+	"example.com/layers/store"
+)
+
+func Total(items []string) int {
+	return len(items) * 10
+}
+
+func Discounted(id string) []string {
+	return lookup(id)
+}
+
+func lookup(id string) []string {
+	return store.Get(id)
+}
@@ -0,0 +1,303 @@
+package callback
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/nondeterminism"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "impure-callback"
+
+// Enforce that Impurity implements the Finding and Aggregate types
+var (
+	_ finding.Finding   = (*Impurity)(nil)
+	_ finding.Aggregate = (*Impurity)(nil)
+)
+
+// Defaults lists the higher-order functions and methods in the standard
+// library whose callback arguments are expected to be pure. Methods are named
+// like "(*sync.Once).Do".
+var Defaults = []config.Callback{
+	{Function: "sort.Slice", Arguments: []int{1}},
+	{Function: "sort.SliceStable", Arguments: []int{1}},
+	{Function: "sort.Search", Arguments: []int{1}},
+	{Function: "slices.SortFunc", Arguments: []int{1}},
+	{Function: "slices.SortStableFunc", Arguments: []int{1}},
+	{Function: "slices.IndexFunc", Arguments: []int{1}},
+	{Function: "slices.ContainsFunc", Arguments: []int{1}},
+	{Function: "slices.DeleteFunc", Arguments: []int{1}},
+	{Function: "slices.CompactFunc", Arguments: []int{1}},
+	{Function: "slices.EqualFunc", Arguments: []int{2}},
+	{Function: "slices.BinarySearchFunc", Arguments: []int{2}},
+	{Function: "slices.MaxFunc", Arguments: []int{1}},
+	{Function: "slices.MinFunc", Arguments: []int{1}},
+	{Function: "maps.DeleteFunc", Arguments: []int{1}},
+	{Function: "strings.Map", Arguments: []int{0}},
+	{Function: "strings.FieldsFunc", Arguments: []int{1}},
+	{Function: "strings.IndexFunc", Arguments: []int{1}},
+	{Function: "strings.LastIndexFunc", Arguments: []int{1}},
+	{Function: "strings.TrimFunc", Arguments: []int{1}},
+	{Function: "strings.TrimLeftFunc", Arguments: []int{1}},
+	{Function: "strings.TrimRightFunc", Arguments: []int{1}},
+	{Function: "bytes.Map", Arguments: []int{0}},
+	{Function: "sync.OnceValue", Arguments: []int{0}},
+	{Function: "sync.OnceValues", Arguments: []int{0}},
+	{Function: "(*sync.Once).Do", Arguments: []int{0}},
+}
+
+// Impurity describes something a callback does that makes it impure: a write
+// to a variable it captures, or a call with effects. When the callback is a
+// named function rather than a function literal, the impurity is the
+// function's effects.
+type Impurity struct {
+	// node is the offending statement or call inside a function literal, or
+	// the argument itself for named functions.
+	node ast.Node
+
+	// higherOrder is the name of the function the callback is passed to.
+	higherOrder string
+	argument    int
+
+	// captured is set for writes to captured variables.
+	captured *ast.Ident
+
+	// callee and effects are set for calls with effects.
+	callee  string
+	effects effect.Summary
+}
+
+func (i Impurity) Message(fset *token.FileSet) string {
+	prefix := fmt.Sprintf("callback passed to %s (argument %d) must be pure", i.higherOrder, i.argument+1)
+
+	switch {
+	case i.captured != nil:
+		return fmt.Sprintf("%s, but it modifies captured variable %q", prefix, i.captured.Name)
+
+	case i.callee != "":
+		return fmt.Sprintf("%s, but it calls %s, which has effects: %s", prefix, i.callee, i.effects)
+	}
+
+	return fmt.Sprintf("%s, but %s has effects: %s", prefix, funkyAST.Render(i.node, fset), i.effects)
+}
+
+func (i Impurity) Type() finding.Type {
+	return Type
+}
+
+func (i Impurity) Node() ast.Node {
+	return i.node
+}
+
+func (i Impurity) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(i.node.Pos()).String())
+}
+
+func (i Impurity) String() string {
+	return fmt.Sprintf("impure callback passed to %s", i.higherOrder)
+}
+
+// Subsumes returns the offending node, which the mutation and effect rules
+// would otherwise report on their own.
+func (i Impurity) Subsumes() []ast.Node {
+	return []ast.Node{i.node}
+}
+
+// SubsumedTypes returns the types of the mutation, effect and nondeterminism
+// findings that the callback's offending node would otherwise get.
+func (i Impurity) SubsumedTypes() []finding.Type {
+	return append([]finding.Type{effect.Type, nondeterminism.Type}, mutation.Types...)
+}
+
+// FindInFiles checks the callbacks passed to the configured higher-order
+// functions against catalog. When g isn't nil, calls are checked for the effects of the
+// functions they call in turn.
+func FindInFiles(files []*ast.File, info *types.Info, c config.Config, catalog effect.Catalog, g *effect.Graph) []Impurity {
+	if info == nil {
+		return nil
+	}
+
+	positions := Positions(c)
+
+	var result []Impurity
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			callee := typeutil.Callee(info, call)
+			if callee == nil {
+				return true
+			}
+
+			name := effect.ObjectName(origin(callee))
+
+			for _, argument := range positions[name] {
+				if argument >= len(call.Args) {
+					continue
+				}
+
+				check := checker{
+					info:        info,
+					catalog:     catalog,
+					graph:       g,
+					higherOrder: name,
+					argument:    argument,
+				}
+
+				result = append(result, check.arg(call.Args[argument])...)
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(impurities []Impurity) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, i := range impurities {
+		findings = append(findings, i)
+	}
+
+	return findings
+}
+
+// Positions maps the names of the built-in and configured higher-order
+// functions to the positions of their callback arguments.
+func Positions(c config.Config) map[string][]int {
+	result := make(map[string][]int)
+
+	for _, cb := range append(append([]config.Callback(nil), Defaults...), c.Callbacks...) {
+		result[cb.Function] = append(result[cb.Function], cb.Arguments...)
+	}
+
+	return result
+}
+
+type checker struct {
+	info    *types.Info
+	catalog effect.Catalog
+	graph   *effect.Graph
+
+	higherOrder string
+	argument    int
+}
+
+func (c checker) arg(arg ast.Expr) []Impurity {
+	switch a := ast.Unparen(arg).(type) {
+	case *ast.FuncLit:
+		return c.funcLit(a)
+
+	case *ast.Ident, *ast.SelectorExpr:
+		// A named function (or method value) is checked as a whole, since its
+		// body may not be available.
+		fn, ok := c.info.Uses[funcIdent(a)].(*types.Func)
+		if !ok {
+			return nil
+		}
+
+		summary := c.graph.OfFunc(fn)
+		if effects := effect.Lookup(c.catalog, fn); len(effects) > 0 {
+			summary = effect.Summary{Effects: effects}
+		}
+
+		if len(summary.Effects) == 0 {
+			return nil
+		}
+
+		return []Impurity{{node: arg, higherOrder: c.higherOrder, argument: c.argument, effects: summary}}
+	}
+
+	return nil
+}
+
+func (c checker) funcLit(lit *ast.FuncLit) []Impurity {
+	var result []Impurity
+
+	ast.Inspect(lit.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				break
+			}
+
+			for _, lhs := range n.Lhs {
+				if ident := c.capturedRoot(lhs, lit); ident != nil {
+					result = append(result, Impurity{node: n, higherOrder: c.higherOrder, argument: c.argument, captured: ident})
+					break
+				}
+			}
+
+		case *ast.IncDecStmt:
+			if ident := c.capturedRoot(n.X, lit); ident != nil {
+				result = append(result, Impurity{node: n, higherOrder: c.higherOrder, argument: c.argument, captured: ident})
+			}
+
+		case *ast.CallExpr:
+			effects, name := effect.OfCall(n, c.info, c.catalog)
+			summary := effect.Summary{Effects: effects}
+
+			if len(effects) == 0 {
+				summary = c.graph.OfCallSite(n)
+				if callee := typeutil.Callee(c.info, n); callee != nil {
+					name = effect.ObjectName(callee)
+				}
+			}
+
+			if len(summary.Effects) > 0 {
+				result = append(result, Impurity{node: n, higherOrder: c.higherOrder, argument: c.argument, callee: name, effects: summary})
+			}
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// capturedRoot returns the variable being written to by expr, if it's declared
+// outside of the function literal.
+func (c checker) capturedRoot(expr ast.Expr, lit *ast.FuncLit) *ast.Ident {
+	ident := funkyAST.RootIdentFromExpr(expr)
+	if ident == nil || funkyAST.BlankIdentifier(ident) {
+		return nil
+	}
+
+	v, ok := c.info.ObjectOf(ident).(*types.Var)
+	if !ok || (v.Pos() >= lit.Pos() && v.Pos() < lit.End()) {
+		return nil
+	}
+
+	return ident
+}
+
+func funcIdent(expr ast.Expr) *ast.Ident {
+	if selector, ok := expr.(*ast.SelectorExpr); ok {
+		return selector.Sel
+	}
+
+	ident, _ := expr.(*ast.Ident)
+	return ident
+}
+
+func origin(obj types.Object) types.Object {
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.Origin()
+	}
+
+	return obj
+}
@@ -0,0 +1,53 @@
+package callback
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableImpurity struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableImpurity{
+		{"testdata/callbacks/main.go:22:3", `callback passed to sort.Slice (argument 2) must be pure, but it modifies captured variable "comparisons"`},
+		{"testdata/callbacks/main.go:31:3", "callback passed to sort.SliceStable (argument 2) must be pure, but it calls fmt.Println, which has effects: stdout"},
+		{"testdata/callbacks/main.go:46:3", `callback passed to strings.Map (argument 1) must be pure, but it modifies captured variable "seen"`},
+		{"testdata/callbacks/main.go:61:27", "callback passed to main.mapStrings (argument 2) must be pure, but os.Getenv has effects: environment"},
+		{"testdata/callbacks/main.go:71:3", `callback passed to (*sync.Once).Do (argument 1) must be pure, but it modifies captured variable "loads"`},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "callbacks/main.go")
+
+	c := config.Default()
+	c.Callbacks = []config.Callback{{Function: "main.mapStrings", Arguments: []int{1}}}
+
+	impurities := FindInFiles([]*ast.File{file}, info, c, config.Catalog(c), nil)
+
+	if len(impurities) != len(expected) {
+		for _, i := range impurities {
+			t.Log(i.Location(fset), i.Message(fset))
+		}
+
+		t.Fatalf("expected %d impurities, but found %d", len(expected), len(impurities))
+	}
+
+	for i, impurity := range impurities {
+		actual := testableImpurity{
+			location: impurity.Location(fset),
+			message:  impurity.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
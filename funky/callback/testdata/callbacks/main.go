@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+func sortByName(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+}
+
+func sortCounting(names []string) int {
+	comparisons := 0
+
+	sort.Slice(names, func(i, j int) bool {
+		comparisons++
+		return names[i] < names[j]
+	})
+
+	return comparisons
+}
+
+func sortLogging(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		fmt.Println("comparing", names[i], names[j])
+		return names[i] < names[j]
+	})
+}
+
+func shout(s string) string {
+	return strings.Map(unicode.ToUpper, s)
+}
+
+func lower(s string) string {
+	seen := make(map[rune]bool)
+
+	return strings.Map(func(r rune) rune {
+		lowered := unicode.ToUpper(r)
+		lowered = unicode.ToLower(lowered)
+		seen[r] = true
+		return lowered
+	}, s)
+}
+
+func mapStrings(items []string, f func(string) string) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, f(item))
+	}
+
+	return result
+}
+
+func lookupAll(names []string) []string {
+	return mapStrings(names, os.Getenv)
+}
+
+var (
+	loadOnce sync.Once
+	loads    int
+)
+
+func load() {
+	loadOnce.Do(func() {
+		loads++
+	})
+}
+
+func main() {
+	sortByName(os.Args)
+	_ = sortCounting(os.Args)
+	sortLogging(os.Args)
+	_ = shout("a")
+	_ = lower("A")
+	_ = lookupAll(os.Args)
+	load()
+}
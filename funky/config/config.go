@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/spf13/viper"
+)
+
+// Level determines what happens when a rule produces a finding.
+type Level string
+
+const (
+	// Allow hides the rule's findings.
+	Allow Level = "allow"
+
+	// Warn reports the rule's findings.
+	Warn Level = "warn"
+
+	// Enforce reports the rule's findings and causes Funky to fail.
+	Enforce Level = "enforce"
+)
+
+// Config holds the settings that control Funky's rules. It's usually read from
+// a .funky.yaml file.
+type Config struct {
+	// Rules maps finding types (e.g. "mutation" or "err-reuse") to the level
+	// at which they're reported. Rules that aren't listed default to Warn.
+	Rules map[string]Level `mapstructure:"rules"`
+
+	Accumulators Accumulators `mapstructure:"accumulators"`
+	NamedResults NamedResults `mapstructure:"named-results"`
+
+	// Effects extends Funky's built-in catalog of the functions, variables
+	// and packages that have effects.
+	Effects []effect.Entry `mapstructure:"effects"`
+
+	Boundaries Boundaries `mapstructure:"boundaries"`
+
+	// Callbacks extends the built-in list of higher-order functions whose
+	// callback arguments must be pure.
+	Callbacks []Callback `mapstructure:"callbacks"`
+
+	Immutable Immutable `mapstructure:"immutable"`
+
+	MethodMutations MethodMutations `mapstructure:"method-mutations"`
+
+	Channels Channels `mapstructure:"channels"`
+}
+
+// Channels configures how channel operations are treated by the effect rules.
+type Channels struct {
+	// AllowIn lists the packages, as import path patterns, whose channel
+	// sends, receives, closes and select statements aren't counted as
+	// communication effects. A pattern ending in "/..." matches the package
+	// and every package beneath it.
+	AllowIn []string `mapstructure:"allow-in"`
+}
+
+// MethodMutations configures the reporting of calls to pointer-receiver methods
+// that modify the variable they're called on.
+type MethodMutations struct {
+	// AllowedTypes extends the built-in list of types, like strings.Builder
+	// and sync.WaitGroup, that are meant to be modified through their methods.
+	// Types are given by their full name, e.g. "github.com/example/cache.LRU".
+	AllowedTypes []string `mapstructure:"allowed-types"`
+}
+
+// Immutable configures the checking of types marked with //funky:immutable.
+type Immutable struct {
+	// Constructors lists the name prefixes of the functions that are allowed
+	// to write to the fields of an immutable type declared in the same
+	// package. It defaults to "New".
+	Constructors []string `mapstructure:"constructors"`
+}
+
+// Callback identifies the arguments of a higher-order function, like the less
+// function passed to sort.Slice, that must be pure functions.
+type Callback struct {
+	// Function is the full name of the function or method, e.g. "sort.Slice"
+	// or "github.com/example/fp.Map".
+	Function string `mapstructure:"function"`
+
+	// Arguments are the zero-based positions of the callback arguments.
+	Arguments []int `mapstructure:"arguments"`
+}
+
+// Boundaries separates a program's functional core, which must be effect-free,
+// from its imperative shell, which is allowed to perform effects such as I/O.
+//
+// Packages are given as import path patterns. A pattern ending in "/..."
+// matches the package and every package beneath it.
+type Boundaries struct {
+	// Core lists the packages that must not call, directly or transitively,
+	// any function that has effects.
+	Core []string `mapstructure:"core"`
+
+	// Shell lists the packages that may perform effects. Calls from the core
+	// into a shell package are reported even when the callee has no known effects.
+	Shell []string `mapstructure:"shell"`
+}
+
+// Accumulators configures the detection of accumulator loops.
+type Accumulators struct {
+	// HelperPackage is the import path of a package providing generic Map,
+	// Filter and Reduce functions. When set, accumulator findings include a
+	// suggested rewrite that uses this package.
+	HelperPackage string `mapstructure:"helper-package"`
+}
+
+// NamedResults configures how writes to named result parameters are reported.
+type NamedResults struct {
+	// AllowInDefer exempts writes made from deferred functions, as in the
+	// recover and error-wrapping idioms, so that only other writes are reported.
+	AllowInDefer bool `mapstructure:"allow-in-defer"`
+}
+
+func Default() Config {
+	return Config{
+		Rules: map[string]Level{
+			// Labels every call site that has an effect, so it's opt-in.
+			string(effect.Type): Allow,
+		},
+		Immutable: Immutable{
+			Constructors: []string{"New"},
+		},
+	}
+}
+
+// Catalog returns the built-in effect catalog, extended with the configured entries.
+func Catalog(c Config) effect.Catalog {
+	return effect.AllowChannelsIn(effect.Merge(effect.DefaultCatalog(), effect.NewCatalog(c.Effects...)), c.Channels.AllowIn...)
+}
+
+// RuleLevel returns the level configured for findings of type t.
+func RuleLevel(c Config, t finding.Type) Level {
+	if level, ok := c.Rules[string(t)]; ok {
+		return level
+	}
+
+	return Warn
+}
+
+// Validate checks that every configured rule level is known, that every
+// effect catalog entry and callback is well-formed, and that no package
+// pattern is declared as both core and shell.
+func Validate(c Config) error {
+	for _, entry := range c.Effects {
+		if err := effect.ValidateEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	for _, cb := range c.Callbacks {
+		if cb.Function == "" || len(cb.Arguments) == 0 {
+			return fmt.Errorf("callback %+v must set a function and at least one argument position", cb)
+		}
+
+		for _, arg := range cb.Arguments {
+			if arg < 0 {
+				return fmt.Errorf("callback %q has negative argument position %d", cb.Function, arg)
+			}
+		}
+	}
+
+	for _, pattern := range c.Boundaries.Core {
+		for _, shell := range c.Boundaries.Shell {
+			if pattern == shell {
+				return fmt.Errorf("package pattern %q can't be part of both the core and the shell", pattern)
+			}
+		}
+	}
+
+	for rule, level := range c.Rules {
+		switch level {
+		case Allow, Warn, Enforce:
+		default:
+			return fmt.Errorf("rule %q has unknown level %q (expected %q, %q or %q)", rule, level, Allow, Warn, Enforce)
+		}
+	}
+
+	return nil
+}
+
+// FromViper decodes the configuration held by v on top of the default configuration.
+func FromViper(v *viper.Viper) (Config, error) {
+	c := Default()
+
+	err := v.Unmarshal(&c)
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = Validate(c)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return c, nil
+}
+
+// Load reads the configuration from the file at path. An empty path results in
+// the default configuration.
+func Load(path string) (Config, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	err := v.ReadInConfig()
+	if err != nil {
+		return Config{}, err
+	}
+
+	return FromViper(v)
+}
@@ -0,0 +1,435 @@
+package construction
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"golang.org/x/tools/go/analysis"
+)
+
+var Type finding.Type = "construction-by-mutation"
+
+// Enforce that Construction implements the Finding, Fixer and Aggregate types
+var (
+	_ finding.Finding   = (*Construction)(nil)
+	_ finding.Fixer     = (*Construction)(nil)
+	_ finding.Aggregate = (*Construction)(nil)
+)
+
+// minWrites is the number of writes that make a construction worth reporting.
+// A single write after the declaration, like setting one option on a value
+// returned by a constructor, reads as plainly as a composite literal would.
+const minWrites = 2
+
+// Construction describes a struct or map that is declared and then filled in
+// by a sequence of field or element writes, e.g. `var c Config; c.A = 1; c.B = 2`.
+type Construction struct {
+	decl   ast.Stmt
+	name   *ast.Ident
+	writes []*ast.AssignStmt
+
+	// literal is the composite literal the variable was declared with, if any.
+	literal *ast.CompositeLit
+
+	// typeExpr is the type of the value being constructed.
+	typeExpr ast.Expr
+
+	// pointer is true when the variable holds the address of the literal (`c := &T{}`).
+	pointer bool
+
+	// file is used to make sure a suggested fix won't drop any comments.
+	file *ast.File
+
+	// info is used to make sure a suggested fix only names fields the
+	// constructed type declares itself, and may be nil.
+	info *types.Info
+}
+
+func (c Construction) Message(fset *token.FileSet) string {
+	return fmt.Sprintf("%q is constructed by %d %s after its declaration; use a composite literal instead", c.name.Name, len(c.writes), c.writeKind())
+}
+
+func (c Construction) Type() finding.Type {
+	return Type
+}
+
+func (c Construction) Node() ast.Node {
+	return c.decl
+}
+
+func (c Construction) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(c.decl.Pos()).String())
+}
+
+func (c Construction) String() string {
+	return fmt.Sprintf("%q constructed by mutation", c.name.Name)
+}
+
+func (c Construction) Subsumes() []ast.Node {
+	var nodes []ast.Node
+
+	for _, w := range c.writes {
+		nodes = append(nodes, w)
+	}
+
+	return nodes
+}
+
+// SubsumedTypes returns the mutation types, since each field or key write is
+// otherwise reported as a mutation.
+func (c Construction) SubsumedTypes() []finding.Type {
+	return mutation.Types
+}
+
+// SuggestedFixes folds the writes into the composite literal. No fix is
+// suggested when that would change the program's meaning, e.g. when a written
+// value refers to the variable under construction, or when a key is written
+// more than once, or when it wouldn't compile, e.g. when a written field is
+// promoted from an embedded struct.
+func (c Construction) SuggestedFixes(fset *token.FileSet) []analysis.SuggestedFix {
+	if !c.foldable() {
+		return nil
+	}
+
+	var elements []string
+
+	if c.literal != nil {
+		for _, elt := range c.literal.Elts {
+			elements = append(elements, funkyAST.Render(elt, fset))
+		}
+	}
+
+	for _, w := range c.writes {
+		elements = append(elements, fmt.Sprintf("%s: %s", funkyAST.Render(writeKey(w), fset), funkyAST.Render(w.Rhs[0], fset)))
+	}
+
+	prefix := ""
+	if c.pointer {
+		prefix = "&"
+	}
+
+	newText := fmt.Sprintf("%s := %s%s{\n%s,\n}", c.name.Name, prefix, funkyAST.Render(c.typeExpr, fset), strings.Join(elements, ",\n"))
+
+	return []analysis.SuggestedFix{
+		{
+			Message: fmt.Sprintf("Construct %q with a composite literal", c.name.Name),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos:     c.decl.Pos(),
+					End:     c.writes[len(c.writes)-1].End(),
+					NewText: []byte(newText),
+				},
+			},
+		},
+	}
+}
+
+func (c Construction) foldable() bool {
+	keys := make(map[string]struct{})
+
+	if c.literal != nil {
+		for _, elt := range c.literal.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return false
+			}
+
+			keys[types.ExprString(kv.Key)] = struct{}{}
+		}
+	}
+
+	for _, w := range c.writes {
+		key := types.ExprString(writeKey(w))
+		if _, duplicate := keys[key]; duplicate {
+			return false
+		}
+
+		keys[key] = struct{}{}
+
+		if references(w.Rhs[0], c.name.Name) || references(writeKey(w), c.name.Name) {
+			return false
+		}
+
+		if c.promoted(w) {
+			return false
+		}
+	}
+
+	return !hasCommentsBetween(c.file, c.decl.Pos(), c.writes[len(c.writes)-1].End())
+}
+
+// promoted reports whether w writes a field that is promoted from an embedded
+// struct, which can't be named in a composite literal of the outer type.
+func (c Construction) promoted(w *ast.AssignStmt) bool {
+	selector, ok := w.Lhs[0].(*ast.SelectorExpr)
+	if !ok || c.info == nil {
+		return false
+	}
+
+	selection := c.info.Selections[selector]
+
+	return selection != nil && len(selection.Index()) > 1
+}
+
+func (c Construction) writeKind() string {
+	noun := "field write"
+	if _, ok := c.writes[0].Lhs[0].(*ast.IndexExpr); ok {
+		noun = "element write"
+	}
+
+	if len(c.writes) == 1 {
+		return noun
+	}
+
+	return noun + "s"
+}
+
+// FindInFiles reports the constructions in the files. When info is nil,
+// fields promoted from embedded structs can't be told apart from the others,
+// so fixes may be suggested that don't compile.
+func FindInFiles(files []*ast.File, info *types.Info) []Construction {
+	var result []Construction
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if list := stmtList(node); list != nil {
+				result = append(result, findInStmtList(list, file, info)...)
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(constructions []Construction) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, c := range constructions {
+		findings = append(findings, c)
+	}
+
+	return findings
+}
+
+func findInStmtList(list []ast.Stmt, file *ast.File, info *types.Info) []Construction {
+	var result []Construction
+
+	for i, stmt := range list {
+		c, ok := declaration(stmt)
+		if !ok {
+			continue
+		}
+
+		for _, next := range list[i+1:] {
+			w := write(next, c.name.Name, c.isMap())
+			if w == nil {
+				break
+			}
+
+			c.writes = append(c.writes, w)
+		}
+
+		if len(c.writes) >= minWrites {
+			c.file = file
+			c.info = info
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// declaration matches statements that declare a struct or map value that can
+// be expressed as a composite literal: `var c T`, `c := T{...}`, `c := &T{...}`,
+// `var c = T{...}` and `m := make(map[K]V)`.
+func declaration(stmt ast.Stmt) (Construction, bool) {
+	switch s := stmt.(type) {
+	case *ast.DeclStmt:
+		genDecl, ok := s.Decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+			return Construction{}, false
+		}
+
+		spec, ok := genDecl.Specs[0].(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || funkyAST.BlankIdentifier(spec.Names[0]) {
+			return Construction{}, false
+		}
+
+		if len(spec.Values) == 0 {
+			// Writes to the zero value of a map or pointer would panic, so only
+			// named and struct types are considered here.
+			switch spec.Type.(type) {
+			case *ast.Ident, *ast.SelectorExpr, *ast.StructType:
+				return Construction{decl: s, name: spec.Names[0], typeExpr: spec.Type}, true
+			}
+
+			return Construction{}, false
+		}
+
+		if len(spec.Values) == 1 && spec.Type == nil {
+			return fromValue(s, spec.Names[0], spec.Values[0])
+		}
+
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+			return Construction{}, false
+		}
+
+		name := funkyAST.IdentFromExpr(s.Lhs[0])
+		if name == nil || funkyAST.BlankIdentifier(name) {
+			return Construction{}, false
+		}
+
+		return fromValue(s, name, s.Rhs[0])
+	}
+
+	return Construction{}, false
+}
+
+func fromValue(decl ast.Stmt, name *ast.Ident, value ast.Expr) (Construction, bool) {
+	c := Construction{decl: decl, name: name}
+
+	switch v := value.(type) {
+	case *ast.CompositeLit:
+		if v.Type == nil {
+			return Construction{}, false
+		}
+
+		c.literal = v
+		c.typeExpr = v.Type
+
+		return c, true
+
+	case *ast.UnaryExpr:
+		lit, ok := v.X.(*ast.CompositeLit)
+		if v.Op != token.AND || !ok || lit.Type == nil {
+			return Construction{}, false
+		}
+
+		c.literal = lit
+		c.typeExpr = lit.Type
+		c.pointer = true
+
+		return c, true
+
+	case *ast.CallExpr:
+		// make(map[K]V) or make(map[K]V, size)
+		if ident := funkyAST.IdentFromExpr(v.Fun); ident == nil || ident.Name != "make" || len(v.Args) == 0 {
+			return Construction{}, false
+		}
+
+		if _, ok := v.Args[0].(*ast.MapType); !ok {
+			return Construction{}, false
+		}
+
+		c.typeExpr = v.Args[0]
+
+		return c, true
+	}
+
+	return Construction{}, false
+}
+
+// write matches `name.Field = value` (or `name[key] = value` for maps).
+func write(stmt ast.Stmt, name string, isMap bool) *ast.AssignStmt {
+	assignStmt, ok := stmt.(*ast.AssignStmt)
+	if !ok || assignStmt.Tok != token.ASSIGN || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return nil
+	}
+
+	var target ast.Expr
+
+	switch lhs := assignStmt.Lhs[0].(type) {
+	case *ast.SelectorExpr:
+		if isMap {
+			return nil
+		}
+
+		target = lhs.X
+
+	case *ast.IndexExpr:
+		if !isMap {
+			return nil
+		}
+
+		target = lhs.X
+
+	default:
+		return nil
+	}
+
+	if ident := funkyAST.IdentFromExpr(target); ident == nil || ident.Name != name {
+		return nil
+	}
+
+	return assignStmt
+}
+
+func (c Construction) isMap() bool {
+	_, ok := c.typeExpr.(*ast.MapType)
+	return ok
+}
+
+// writeKey returns the field name or map key being written.
+func writeKey(w *ast.AssignStmt) ast.Expr {
+	switch lhs := w.Lhs[0].(type) {
+	case *ast.SelectorExpr:
+		return lhs.Sel
+
+	case *ast.IndexExpr:
+		return lhs.Index
+	}
+
+	return nil
+}
+
+func references(expr ast.Expr, name string) bool {
+	found := false
+
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func hasCommentsBetween(file *ast.File, start, end token.Pos) bool {
+	if file == nil {
+		return false
+	}
+
+	for _, group := range file.Comments {
+		if group.Pos() >= start && group.End() <= end {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stmtList(node ast.Node) []ast.Stmt {
+	switch n := node.(type) {
+	case *ast.BlockStmt:
+		return n.List
+
+	case *ast.CaseClause:
+		return n.Body
+
+	case *ast.CommClause:
+		return n.Body
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package construction
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+)
+
+func TestFindInFiles(t *testing.T) {
+	expected := []finding.Location{
+		"testdata/writes/main.go:10:2",
+		"testdata/writes/main.go:17:2",
+		"testdata/writes/main.go:24:2",
+		"testdata/writes/main.go:31:2",
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "testdata/writes/main.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("unable to load Go source test fixture: %v", err)
+	}
+
+	constructions := FindInFiles([]*ast.File{file}, nil)
+
+	if len(constructions) != len(expected) {
+		t.Fatalf("expected %d constructions, but found %d", len(expected), len(constructions))
+	}
+
+	for i, c := range constructions {
+		if actual := c.Location(fset); actual != expected[i] {
+			t.Errorf("expected construction at %s, but found it at %s", expected[i], actual)
+		}
+	}
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name: "struct",
+			body: `	var c Config
+	c.Name = "funky"
+	c.Retries = n`,
+			expected: `	c := Config{
+		Name:    "funky",
+		Retries: n,
+	}`,
+		},
+		{
+			name: "existing literal",
+			body: `	c := &Config{Name: "funky"}
+	c.Retries = n
+	c.Verbose = true`,
+			expected: `	c := &Config{
+		Name:    "funky",
+		Retries: n,
+		Verbose: true,
+	}`,
+		},
+		{
+			name: "map",
+			body: `	m := make(map[string]int)
+	m["a"] = 1
+	m["b"] = n`,
+			expected: `	m := map[string]int{
+		"a": 1,
+		"b": n,
+	}`,
+		},
+		{
+			name: "value depends on the variable",
+			body: `	var c Config
+	c.Name = "funky"
+	c.Retries = len(c.Name)`,
+		},
+		{
+			name: "duplicate key",
+			body: `	c := Config{Name: "a"}
+	c.Retries = n
+	c.Name = "b"`,
+		},
+		{
+			name: "promoted field",
+			body: `	var c Config
+	c.Name = "funky"
+	c.Region = "eu"`,
+		},
+	}
+
+	// The snippets are type-checked so that promoted fields can be recognized.
+	const header = `package p
+
+type Config struct {
+	Name    string
+	Retries int
+	Verbose bool
+	Location
+}
+
+type Location struct {
+	Region string
+}
+
+func f(n int) {
+`
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := header + tc.body + "\n}\n"
+
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info := &types.Info{
+				Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			}
+
+			if _, err := (&types.Config{}).Check("p", fset, []*ast.File{file}, info); err != nil {
+				t.Fatal(err)
+			}
+
+			constructions := FindInFiles([]*ast.File{file}, info)
+			if len(constructions) != 1 {
+				t.Fatalf("expected 1 construction, but found %d", len(constructions))
+			}
+
+			fixes := constructions[0].SuggestedFixes(fset)
+
+			if tc.expected == "" {
+				if len(fixes) != 0 {
+					t.Fatalf("expected no suggested fixes, but found %d", len(fixes))
+				}
+
+				return
+			}
+
+			if len(fixes) != 1 || len(fixes[0].TextEdits) != 1 {
+				t.Fatalf("expected a single suggested fix with a single edit")
+			}
+
+			edit := fixes[0].TextEdits[0]
+			fixed := src[:fset.Position(edit.Pos).Offset] + string(edit.NewText) + src[fset.Position(edit.End).Offset:]
+
+			formatted, err := format.Source([]byte(fixed))
+			if err != nil {
+				t.Fatalf("unable to format fixed source: %v\n%s", err, fixed)
+			}
+
+			expected := header + tc.expected + "\n}\n"
+			if actual := string(formatted); actual != expected {
+				t.Errorf("unexpected fixed source:\n%s", actual)
+			}
+		})
+	}
+}
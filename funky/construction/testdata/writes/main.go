@@ -0,0 +1,56 @@
+package main
+
+type Config struct {
+	Name    string
+	Retries int
+	Verbose bool
+}
+
+func structFieldWrites() Config {
+	var c Config // construction by mutation
+	c.Name = "funky"
+	c.Retries = 3
+	return c
+}
+
+func literalThenFieldWrites() *Config {
+	c := &Config{Name: "funky"} // construction by mutation
+	c.Retries = 3
+	c.Verbose = true
+	return c
+}
+
+func mapElementWrites() map[string]int {
+	m := map[string]int{} // construction by mutation
+	m["a"] = 1
+	m["b"] = 2
+	return m
+}
+
+func madeMapElementWrites() map[string]int {
+	m := make(map[string]int) // construction by mutation
+	m["a"] = 1
+	m["b"] = 2
+	return m
+}
+
+func singleWrite() Config {
+	var c Config // not construction by mutation: a single write
+	c.Name = "funky"
+	return c
+}
+
+func nilMap() map[string]int {
+	var m map[string]int // not construction by mutation: writing to a nil map panics
+	print(m)
+	return m
+}
+
+func writeAfterOtherStatement() Config {
+	var c Config // not construction by mutation: the write doesn't immediately follow the declaration
+	print(c.Name)
+	c.Name = "funky"
+	return c
+}
+
+func main() {}
@@ -0,0 +1,205 @@
+package effect
+
+import (
+	_ "embed" // for the built-in catalog
+	"fmt"
+	"go/types"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed catalog.yaml
+var builtinCatalogData []byte
+
+// Entry classifies a single function, variable or package. Exactly one of
+// Function, Variable and Package should be set.
+type Entry struct {
+	// Function is the full name of a function or method, e.g. "os.WriteFile"
+	// or "(*os.File).Write".
+	Function string `yaml:"function" mapstructure:"function"`
+
+	// Variable is the name of a package-level variable, e.g. "os.Args".
+	Variable string `yaml:"variable" mapstructure:"variable"`
+
+	// Package is an import path. Its effects apply to the package-level
+	// functions and variables of the package, but not to methods, whose
+	// receivers may belong to the caller (e.g. a *flag.FlagSet it created).
+	Package string `yaml:"package" mapstructure:"package"`
+
+	Effects []Kind `yaml:"effects" mapstructure:"effects"`
+}
+
+// Catalog classifies functions, methods, variables and packages by the
+// effects they have.
+type Catalog struct {
+	// objects is keyed by the names returned by ObjectName.
+	objects  map[string]Set
+	packages map[string]Set
+
+	// channelsAllowed lists the package patterns in which channel operations
+	// aren't counted as effects.
+	channelsAllowed []string
+}
+
+// NewCatalog builds a catalog from a list of entries.
+func NewCatalog(entries ...Entry) Catalog {
+	c := Catalog{
+		objects:  make(map[string]Set),
+		packages: make(map[string]Set),
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.Function != "":
+			c.objects[e.Function] = Union(c.objects[e.Function], NewSet(e.Effects...))
+		case e.Variable != "":
+			c.objects[e.Variable] = Union(c.objects[e.Variable], NewSet(e.Effects...))
+		case e.Package != "":
+			c.packages[e.Package] = Union(c.packages[e.Package], NewSet(e.Effects...))
+		}
+	}
+
+	return c
+}
+
+// DefaultCatalog returns Funky's built-in catalog of the standard library,
+// which is read from the embedded catalog.yaml file.
+func DefaultCatalog() Catalog {
+	return NewCatalog(builtinEntries...)
+}
+
+var builtinEntries = mustParseEntries(builtinCatalogData)
+
+func mustParseEntries(data []byte) []Entry {
+	var entries []Entry
+
+	err := yaml.UnmarshalStrict(data, &entries)
+	if err != nil {
+		panic(fmt.Sprintf("unable to parse built-in effect catalog: %v", err))
+	}
+
+	for _, e := range entries {
+		if err := ValidateEntry(e); err != nil {
+			panic(fmt.Sprintf("invalid entry in built-in effect catalog: %v", err))
+		}
+	}
+
+	return entries
+}
+
+// ValidateEntry checks that an entry names exactly one thing and lists at least one effect.
+func ValidateEntry(e Entry) error {
+	named := 0
+
+	for _, name := range []string{e.Function, e.Variable, e.Package} {
+		if name != "" {
+			named++
+		}
+	}
+
+	if named != 1 {
+		return fmt.Errorf("effect catalog entry %+v must set exactly one of function, variable or package", e)
+	}
+
+	if len(e.Effects) == 0 {
+		return fmt.Errorf("effect catalog entry %+v doesn't list any effects", e)
+	}
+
+	return nil
+}
+
+// Merge returns a catalog containing the entries of both catalogs. When both
+// catalogs classify the same thing, the effects are combined.
+func Merge(a, b Catalog) Catalog {
+	return Catalog{
+		objects:         mergeEntries(a.objects, b.objects),
+		packages:        mergeEntries(a.packages, b.packages),
+		channelsAllowed: append(append([]string(nil), a.channelsAllowed...), b.channelsAllowed...),
+	}
+}
+
+// AllowChannelsIn returns a copy of the catalog in which channel operations
+// made by the packages matching the patterns aren't counted as effects.
+// A pattern ending in "/..." matches the package and every package beneath it.
+func AllowChannelsIn(c Catalog, patterns ...string) Catalog {
+	return Merge(c, Catalog{channelsAllowed: patterns})
+}
+
+// OfChannelOperation returns the effects of a channel operation made by pkg.
+func OfChannelOperation(c Catalog, pkg *types.Package) Set {
+	if pkg != nil && MatchPackage(c.channelsAllowed, pkg.Path()) {
+		return nil
+	}
+
+	return NewSet(Communication)
+}
+
+// MatchPackage reports whether the import path matches any of the patterns.
+// A pattern ending in "/..." matches the package and every package beneath it.
+func MatchPackage(patterns []string, pkgPath string) bool {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+			if pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/") {
+				return true
+			}
+
+			continue
+		}
+
+		if pkgPath == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mergeEntries(a, b map[string]Set) map[string]Set {
+	result := make(map[string]Set)
+
+	for _, entries := range []map[string]Set{a, b} {
+		for name, kinds := range entries {
+			result[name] = Union(result[name], kinds)
+		}
+	}
+
+	return result
+}
+
+// Lookup returns the effects the catalog attributes to obj. Package entries
+// apply to package-level functions and variables, but not to methods, fields
+// and other objects.
+func Lookup(c Catalog, obj types.Object) Set {
+	result := Union(c.objects[ObjectName(obj)])
+
+	if coveredByPackage(obj) {
+		result = Union(result, c.packages[obj.Pkg().Path()])
+	}
+
+	return result
+}
+
+func coveredByPackage(obj types.Object) bool {
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Pkg() != nil && o.Signature().Recv() == nil
+	case *types.Var:
+		return isPackageLevel(o)
+	}
+
+	return false
+}
+
+// ObjectName returns the name used to identify obj in a catalog.
+func ObjectName(obj types.Object) string {
+	if f, ok := obj.(*types.Func); ok {
+		return f.FullName()
+	}
+
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+
+	return obj.Pkg().Path() + "." + obj.Name()
+}
@@ -0,0 +1,61 @@
+package effect
+
+import (
+	"go/importer"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		pkg      string
+		object   string
+		method   string
+		expected string
+	}{
+		{pkg: "os", object: "WriteFile", expected: "filesystem"},
+		{pkg: "os", object: "File", method: "Write", expected: "filesystem"},
+		{pkg: "os", object: "Args", expected: "global-state"},
+		{pkg: "os", object: "Setenv", expected: "environment, global-state"},
+		{pkg: "log", object: "Println", expected: "logging"},
+		{pkg: "log", object: "SetOutput", expected: "global-state, logging"},
+		{pkg: "math/rand", object: "Intn", expected: "randomness"},
+		{pkg: "math/rand", object: "Rand", method: "Intn", expected: ""},
+		{pkg: "flag", object: "Parse", expected: "global-state"},
+		{pkg: "flag", object: "CommandLine", expected: "global-state"},
+		{pkg: "flag", object: "FlagSet", method: "StringVar", expected: ""},
+		{pkg: "os/user", object: "User", method: "GroupIds", expected: "environment"},
+		{pkg: "strings", object: "ToUpper", expected: ""},
+		{pkg: "os", object: "ErrNotExist", expected: ""},
+	}
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	catalog := Merge(DefaultCatalog(), NewCatalog(Entry{Function: "strings.ToLower", Effects: []Kind{"custom"}}))
+
+	for _, tc := range cases {
+		pkg, err := imp.Import(tc.pkg)
+		if err != nil {
+			t.Fatalf("unable to import %s: %v", tc.pkg, err)
+		}
+
+		obj := pkg.Scope().Lookup(tc.object)
+		if tc.method != "" {
+			obj, _, _ = types.LookupFieldOrMethod(types.NewPointer(obj.Type()), false, pkg, tc.method)
+		}
+
+		if actual := Lookup(catalog, obj).String(); actual != tc.expected {
+			t.Errorf("%s: expected effects %q, but found %q", ObjectName(obj), tc.expected, actual)
+		}
+	}
+
+	strings, err := imp.Import("strings")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := Lookup(catalog, strings.Scope().Lookup("ToLower")).String(); actual != "custom" {
+		t.Errorf("expected merged catalog entry to apply, but found %q", actual)
+	}
+}
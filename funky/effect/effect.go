@@ -0,0 +1,144 @@
+package effect
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Kind is a category of effect that a function can have beyond computing its
+// return values.
+type Kind string
+
+const (
+	Filesystem  Kind = "filesystem"
+	Network     Kind = "network"
+	Process     Kind = "process"
+	Stdin       Kind = "stdin"
+	Stdout      Kind = "stdout"
+	Stderr      Kind = "stderr"
+	Logging     Kind = "logging"
+	Time        Kind = "time"
+	Randomness  Kind = "randomness"
+	Environment Kind = "environment"
+	GlobalState Kind = "global-state"
+
+	// Communication is the effect of sending on, receiving from or closing a
+	// channel, including through select statements and range loops.
+	Communication Kind = "communication"
+
+	// Termination is the effect of ending the program or the calling goroutine,
+	// or unwinding the caller's stack, as panic, os.Exit, log.Fatal and
+	// runtime.Goexit do.
+	Termination Kind = "termination"
+)
+
+// Nondeterministic lists the kinds of effect that make a function's result
+// depend on something other than its inputs.
+var Nondeterministic = []Kind{Time, Randomness, Environment}
+
+// Set is a set of effect kinds.
+type Set map[Kind]struct{}
+
+func NewSet(kinds ...Kind) Set {
+	s := make(Set)
+
+	for _, k := range kinds {
+		s[k] = struct{}{}
+	}
+
+	return s
+}
+
+func (s Set) Has(k Kind) bool {
+	_, ok := s[k]
+	return ok
+}
+
+// HasAny reports whether s contains any of the given kinds.
+func (s Set) HasAny(kinds ...Kind) bool {
+	for _, k := range kinds {
+		if s.Has(k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Kinds returns the set's kinds in sorted order.
+func (s Set) Kinds() []Kind {
+	var kinds []Kind
+
+	for k := range s {
+		kinds = append(kinds, k)
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i] < kinds[j]
+	})
+
+	return kinds
+}
+
+func (s Set) String() string {
+	var names []string
+
+	for _, k := range s.Kinds() {
+		names = append(names, string(k))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Union returns a new set containing the kinds of every given set.
+func Union(sets ...Set) Set {
+	result := make(Set)
+
+	for _, s := range sets {
+		for k := range s {
+			result[k] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// OfCall returns the effects of calling the function that call refers to,
+// according to the catalog, along with the name it was looked up by.
+func OfCall(call *ast.CallExpr, info *types.Info, c Catalog) (Set, string) {
+	if info == nil {
+		return nil, ""
+	}
+
+	obj := typeutil.Callee(info, call)
+	if obj == nil {
+		return nil, ""
+	}
+
+	return Lookup(c, obj), ObjectName(obj)
+}
+
+// IsMust reports whether obj is a function following the Must naming
+// convention, like regexp.MustCompile or template.Must: it panics when it's
+// given something invalid, so that package-level variables can be initialized
+// with it, where the panic reports a programming error as the program starts.
+func IsMust(obj types.Object) bool {
+	if _, ok := obj.(*types.Func); !ok {
+		return false
+	}
+
+	for _, prefix := range []string{"Must", "must"} {
+		if rest, ok := strings.CutPrefix(obj.Name(), prefix); ok {
+			r, _ := utf8.DecodeRuneInString(rest)
+			return rest == "" || unicode.IsUpper(r)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,378 @@
+package effect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Summary describes the effects of a function or call site, including the
+// effects of everything it calls.
+type Summary struct {
+	Effects Set
+
+	// Chains holds, for each kind of effect, the sequence of calls that leads
+	// to the effect, starting with the callee and ending with the function or
+	// variable that the catalog attributes the effect to.
+	Chains map[Kind][]string
+}
+
+// String lists the kinds of effect, along with the chain of calls that leads
+// to each one when it comes from further down the call graph.
+func (s Summary) String() string {
+	var descriptions []string
+
+	for _, k := range s.Effects.Kinds() {
+		description := string(k)
+		if chain := s.Chains[k]; len(chain) > 1 {
+			description = fmt.Sprintf("%s (via %s)", k, strings.Join(chain, " → "))
+		}
+
+		descriptions = append(descriptions, description)
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// add merges the effects of s into the summary, with each chain prefixed by
+// the given names. It reports whether any new kinds of effect were added.
+func (s *Summary) add(other Summary, prefix ...string) bool {
+	added := false
+
+	for k := range other.Effects {
+		if s.Effects.Has(k) {
+			continue
+		}
+
+		if s.Effects == nil {
+			s.Effects = NewSet()
+			s.Chains = make(map[Kind][]string)
+		}
+
+		s.Effects[k] = struct{}{}
+		s.Chains[k] = append(append([]string(nil), prefix...), other.Chains[k]...)
+		added = true
+	}
+
+	return added
+}
+
+// Graph holds effect summaries for the functions and call sites of a program.
+// It's built from a call graph over the program's SSA form: static calls are
+// resolved exactly, while calls through interfaces and function values are
+// resolved to every function they might dispatch to, so their effects are
+// over- rather than under-approximated.
+type Graph struct {
+	functions map[*types.Func]Summary
+
+	// calls is keyed by the position of each call's opening parenthesis.
+	calls map[token.Pos]Summary
+}
+
+// BuildGraph computes the effect summaries for every function declared in the
+// given packages. Functions outside of these packages only have the effects
+// the catalog attributes to them.
+func BuildGraph(pkgs []*packages.Package, c Catalog) *Graph {
+	prog, fns := buildProgram(pkgs)
+	cg := vta.CallGraph(ssautil.AllFunctions(prog), cha.CallGraph(prog))
+
+	b := graphBuilder{
+		catalog:   c,
+		callGraph: cg,
+		summaries: make(map[*ssa.Function]Summary),
+	}
+
+	// Effects only ever get added, so repeating this until nothing changes
+	// terminates, including for recursive functions.
+	for changed := true; changed; {
+		changed = false
+
+		for _, fn := range fns {
+			s := b.summaries[fn]
+			for _, site := range b.sites(fn) {
+				if s.add(site.summary) {
+					changed = true
+				}
+			}
+
+			b.summaries[fn] = s
+		}
+	}
+
+	g := &Graph{
+		functions: make(map[*types.Func]Summary),
+		calls:     make(map[token.Pos]Summary),
+	}
+
+	for _, fn := range fns {
+		if obj, ok := origin(fn).Object().(*types.Func); ok {
+			s := g.functions[obj]
+			s.add(b.summaries[fn])
+			g.functions[obj] = s
+		}
+
+		for _, site := range b.sites(fn) {
+			if site.pos.IsValid() {
+				s := g.calls[site.pos]
+				s.add(site.summary)
+				g.calls[site.pos] = s
+			}
+		}
+	}
+
+	return g
+}
+
+// OfCallSite returns the effects of the call, including the effects of
+// everything the callee calls.
+func (g *Graph) OfCallSite(call *ast.CallExpr) Summary {
+	if g == nil {
+		return Summary{}
+	}
+
+	return g.calls[call.Lparen]
+}
+
+// OfFunc returns the effects of calling fn.
+func (g *Graph) OfFunc(fn *types.Func) Summary {
+	if g == nil {
+		return Summary{}
+	}
+
+	return g.functions[fn.Origin()]
+}
+
+type graphBuilder struct {
+	catalog   Catalog
+	callGraph *callgraph.Graph
+	summaries map[*ssa.Function]Summary
+}
+
+type site struct {
+	pos     token.Pos
+	summary Summary
+}
+
+// sites returns the effects of each call and variable use in fn's body, given
+// the summaries computed so far.
+func (b graphBuilder) sites(fn *ssa.Function) []site {
+	callees := make(map[ssa.CallInstruction][]*ssa.Function)
+	if node := b.callGraph.Nodes[fn]; node != nil {
+		for _, edge := range node.Out {
+			callees[edge.Site] = append(callees[edge.Site], edge.Callee.Func)
+		}
+	}
+
+	var pkg *types.Package
+	if p := origin(fn).Pkg; p != nil {
+		pkg = p.Pkg
+	}
+
+	var result []site
+
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			for _, op := range instr.Operands(nil) {
+				// Synthetic globals like the package initialization guard have no object.
+				if global, ok := (*op).(*ssa.Global); ok && global.Object() != nil {
+					if effects := Lookup(b.catalog, global.Object()); len(effects) > 0 {
+						result = append(result, site{summary: direct(global.Object(), effects)})
+					}
+				}
+			}
+
+			if name, ok := channelOperation(instr); ok {
+				result = append(result, site{summary: operation(name, OfChannelOperation(b.catalog, pkg))})
+			}
+
+			// Calls to panic are instructions of their own rather than calls.
+			// The ones SSA adds itself have no position.
+			if p, ok := instr.(*ssa.Panic); ok && p.Pos().IsValid() {
+				panicObj := types.Universe.Lookup("panic")
+				result = append(result, site{pos: p.Pos(), summary: direct(panicObj, Lookup(b.catalog, panicObj))})
+			}
+
+			call, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+
+			var s Summary
+
+			// Some builtins, like the ones SSA uses internally, have no object.
+			if builtin, ok := call.Common().Value.(*ssa.Builtin); ok && builtin.Object() != nil {
+				s.add(direct(builtin.Object(), Lookup(b.catalog, builtin.Object())))
+
+				if builtin.Name() == "close" {
+					s.add(operation("close", OfChannelOperation(b.catalog, pkg)))
+				}
+			}
+
+			targets := callees[call]
+			sort.Slice(targets, func(i, j int) bool {
+				return targets[i].String() < targets[j].String()
+			})
+
+			for _, callee := range targets {
+				s.add(b.summaryOf(callee))
+			}
+
+			result = append(result, site{pos: call.Common().Pos(), summary: s})
+		}
+	}
+
+	return result
+}
+
+// summaryOf returns the effects of calling fn, with chains starting at fn.
+func (b graphBuilder) summaryOf(fn *ssa.Function) Summary {
+	if obj := origin(fn).Object(); obj != nil {
+		if effects := Lookup(b.catalog, obj); len(effects) > 0 {
+			return direct(obj, effects)
+		}
+	}
+
+	var s Summary
+
+	// Wrappers like bound methods would only add noise to the chain, but
+	// instantiations of generic functions, which are also synthetic, are named
+	// after the function they instantiate.
+	if fn.Synthetic != "" && fn.Origin() == nil {
+		s.add(b.summaries[fn])
+	} else {
+		s.add(b.summaries[fn], functionName(fn))
+	}
+
+	return s
+}
+
+func direct(obj types.Object, effects Set) Summary {
+	s := Summary{Effects: effects, Chains: make(map[Kind][]string)}
+
+	for k := range effects {
+		s.Chains[k] = []string{ObjectName(obj)}
+	}
+
+	return s
+}
+
+// operation returns the effects of an operation that isn't a call, like a
+// channel send, with chains naming the operation.
+func operation(name string, effects Set) Summary {
+	s := Summary{Effects: effects, Chains: make(map[Kind][]string)}
+
+	for k := range effects {
+		s.Chains[k] = []string{name}
+	}
+
+	return s
+}
+
+// channelOperation names the channel operation that instr performs, if any.
+// Receives include the ones made by range loops over channels.
+func channelOperation(instr ssa.Instruction) (string, bool) {
+	switch i := instr.(type) {
+	case *ssa.Send:
+		return "channel send", true
+	case *ssa.UnOp:
+		return "channel receive", i.Op == token.ARROW
+	case *ssa.Select:
+		return "select", true
+	}
+
+	return "", false
+}
+
+// buildProgram builds the SSA form of the packages, and returns the functions
+// they declare (including closures and generic instantiations) in a stable order.
+// Dependencies are created from their type information alone.
+func buildProgram(pkgs []*packages.Package) (*ssa.Program, []*ssa.Function) {
+	var fset *token.FileSet
+	if len(pkgs) > 0 {
+		fset = pkgs[0].Fset
+	}
+
+	prog := ssa.NewProgram(fset, ssa.InstantiateGenerics)
+
+	roots := make(map[*types.Package]*packages.Package)
+	for _, pkg := range pkgs {
+		if pkg.Types != nil && pkg.TypesInfo != nil && len(pkg.TypeErrors) == 0 {
+			roots[pkg.Types] = pkg
+		}
+	}
+
+	created := make(map[*types.Package]bool)
+
+	var create func(p *types.Package)
+	create = func(p *types.Package) {
+		if created[p] {
+			return
+		}
+
+		created[p] = true
+
+		if pkg, ok := roots[p]; ok {
+			prog.CreatePackage(p, pkg.Syntax, pkg.TypesInfo, false)
+		} else {
+			prog.CreatePackage(p, nil, nil, true)
+		}
+
+		for _, imported := range p.Imports() {
+			create(imported)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Types != nil {
+			create(pkg.Types)
+		}
+	}
+
+	prog.Build()
+
+	var fns []*ssa.Function
+
+	for fn := range ssautil.AllFunctions(prog) {
+		// Dependencies have no function bodies, except for synthetic wrappers
+		// such as bound methods, which are needed to follow calls through them.
+		if len(fn.Blocks) > 0 {
+			fns = append(fns, fn)
+		}
+	}
+
+	sort.Slice(fns, func(i, j int) bool {
+		if fns[i].Pos() != fns[j].Pos() {
+			return fns[i].Pos() < fns[j].Pos()
+		}
+
+		return fns[i].String() < fns[j].String()
+	})
+
+	return prog, fns
+}
+
+func functionName(fn *ssa.Function) string {
+	if obj := origin(fn).Object(); obj != nil {
+		return ObjectName(obj)
+	}
+
+	return origin(fn).String()
+}
+
+func origin(fn *ssa.Function) *ssa.Function {
+	if o := fn.Origin(); o != nil {
+		return o
+	}
+
+	return fn
+}
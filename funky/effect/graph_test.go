@@ -0,0 +1,151 @@
+package effect
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles_withGraph(t *testing.T) {
+	type testableUse struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableUse{
+		{"testdata/calls/main.go:10:2", "call to example.com/calls.save has effects: filesystem (via example.com/calls.save → example.com/calls/store.Put → (example.com/calls/store.Disk).Save → os.WriteFile), time (via example.com/calls.save → example.com/calls/store.Put → example.com/calls/store.stamp → time.Now)"},
+		{"testdata/calls/main.go:11:6", "call to example.com/calls.countdown has effects: stderr (via example.com/calls.countdown → example.com/calls.tick → println)"},
+		{"testdata/calls/main.go:16:9", "call to example.com/calls/store.Put has effects: filesystem (via example.com/calls/store.Put → (example.com/calls/store.Disk).Save → os.WriteFile), time (via example.com/calls/store.Put → example.com/calls/store.stamp → time.Now)"},
+		{"testdata/calls/main.go:25:9", "call to example.com/calls.tick has effects: stderr (via example.com/calls.tick → println)"},
+		{"testdata/calls/main.go:29:2", "call to println has effects: stderr"},
+		{"testdata/calls/main.go:30:9", "call to example.com/calls.countdown has effects: stderr (via example.com/calls.countdown → example.com/calls.tick → println)"},
+		{"testdata/calls/store/store.go:15:9", "call to os.WriteFile has effects: filesystem"},
+		{"testdata/calls/store/store.go:28:9", "call to (example.com/calls/store.Store).Save has effects: filesystem (via (example.com/calls/store.Disk).Save → os.WriteFile)"},
+		{"testdata/calls/store/store.go:28:22", "call to example.com/calls/store.stamp has effects: time (via example.com/calls/store.stamp → time.Now)"},
+		{"testdata/calls/store/store.go:32:22", "call to time.Now has effects: time"},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "calls")
+	catalog := DefaultCatalog()
+	g := BuildGraph(pkgs, catalog)
+
+	var uses []Use
+	for _, pkg := range pkgs {
+		uses = append(uses, FindInFiles(pkg.Syntax, pkg.Types, pkg.TypesInfo, catalog, g)...)
+	}
+
+	if len(uses) != len(expected) {
+		for _, u := range uses {
+			t.Log(fixture.RelativeLocation(t, u.Location(fset)), u.Message(fset))
+		}
+
+		t.Fatalf("expected %d uses, but found %d", len(expected), len(uses))
+	}
+
+	for i, u := range uses {
+		actual := testableUse{
+			location: fixture.RelativeLocation(t, u.Location(fset)),
+			message:  u.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindInFiles_channels(t *testing.T) {
+	type testableUse struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableUse{
+		{"testdata/channels/main.go:6:2", "send on channel out has effects: communication"},
+		{"testdata/channels/main.go:7:2", "call to close has effects: communication"},
+		{"testdata/channels/main.go:13:2", "range over channel in has effects: communication"},
+		{"testdata/channels/main.go:17:2", "select statement has effects: communication"},
+		{"testdata/channels/main.go:23:17", "receive from channel in has effects: communication"},
+		{"testdata/channels/main.go:28:2", "call to example.com/channels.produce has effects: communication (via example.com/channels.produce → channel send)"},
+		{"testdata/channels/main.go:29:6", "call to example.com/channels.consume has effects: communication (via example.com/channels.consume → channel receive)"},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "channels")
+
+	// Channels are allowed in the pool package, so it's free of communication effects.
+	catalog := AllowChannelsIn(DefaultCatalog(), "example.com/channels/pool/...")
+	g := BuildGraph(pkgs, catalog)
+
+	var uses []Use
+	for _, pkg := range pkgs {
+		uses = append(uses, FindInFiles(pkg.Syntax, pkg.Types, pkg.TypesInfo, catalog, g)...)
+	}
+
+	if len(uses) != len(expected) {
+		for _, u := range uses {
+			t.Log(fixture.RelativeLocation(t, u.Location(fset)), u.Message(fset))
+		}
+
+		t.Fatalf("expected %d uses, but found %d", len(expected), len(uses))
+	}
+
+	for i, u := range uses {
+		actual := testableUse{
+			location: fixture.RelativeLocation(t, u.Location(fset)),
+			message:  u.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindInFiles_generics(t *testing.T) {
+	type testableUse struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableUse{
+		{"testdata/generics/main.go:14:9", "call to os.WriteFile has effects: filesystem"},
+		{"testdata/generics/main.go:18:2", "call to fmt.Println has effects: stdout"},
+		{"testdata/generics/main.go:23:3", "call to example.com/generics.f has effects: stdout (via example.com/generics.Show → fmt.Println)"},
+		{"testdata/generics/main.go:28:2", "call to example.com/generics.Show has effects: stdout (via example.com/generics.Show → fmt.Println)"},
+		{"testdata/generics/main.go:29:2", "call to example.com/generics.Each has effects: stdout (via example.com/generics.Each → example.com/generics.Show → fmt.Println)"},
+		{"testdata/generics/main.go:32:6", "call to (*example.com/generics.Store[K, V]).Save has effects: filesystem (via (*example.com/generics.Store[K, V]).Save → os.WriteFile)"},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "generics")
+	catalog := DefaultCatalog()
+	g := BuildGraph(pkgs, catalog)
+
+	var uses []Use
+	for _, pkg := range pkgs {
+		uses = append(uses, FindInFiles(pkg.Syntax, pkg.Types, pkg.TypesInfo, catalog, g)...)
+	}
+
+	if len(uses) != len(expected) {
+		for _, u := range uses {
+			t.Log(fixture.RelativeLocation(t, u.Location(fset)), u.Message(fset))
+		}
+
+		t.Fatalf("expected %d uses, but found %d", len(expected), len(uses))
+	}
+
+	for i, u := range uses {
+		actual := testableUse{
+			location: fixture.RelativeLocation(t, u.Location(fset)),
+			message:  u.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	"example.com/calls/store"
+)
+
+func main() {
+	save("a", "b")
+	_ = countdown(3)
+	_ = upper("c")
+}
+
+func save(name, contents string) error {
+	return store.Put(store.Disk{}, name, []byte(contents))
+}
+
+// countdown and tick recurse into each other, and only one of them has an effect.
+func countdown(n int) int {
+	if n == 0 {
+		return 0
+	}
+
+	return tick(n)
+}
+
+func tick(n int) int {
+	println(n)
+	return countdown(n - 1)
+}
+
+func upper(s string) string {
+	apply := func(f func(string) string) string {
+		return f(s)
+	}
+
+	return apply(strings.ToUpper)
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"os"
+	"time"
+)
+
+type Store interface {
+	Save(name string, data []byte) error
+}
+
+type Disk struct{}
+
+func (Disk) Save(name string, data []byte) error {
+	return os.WriteFile(name, data, 0o600)
+}
+
+type Memory struct {
+	data map[string][]byte
+}
+
+func (m Memory) Save(name string, data []byte) error {
+	m.data[name] = data
+	return nil
+}
+
+func Put(s Store, name string, data []byte) error {
+	return s.Save(name, stamp(data))
+}
+
+func stamp(data []byte) []byte {
+	return append(data, time.Now().String()...)
+}
@@ -0,0 +1,31 @@
+package main
+
+import "example.com/channels/pool"
+
+func produce(out chan<- int) {
+	out <- 1
+	close(out)
+}
+
+func consume(in <-chan int, done chan struct{}) int {
+	total := 0
+
+	for v := range in {
+		total += v
+	}
+
+	select {
+	case v := <-in:
+		total += v
+	case done <- struct{}{}:
+	}
+
+	return total + <-in
+}
+
+func main() {
+	ch := make(chan int, 1)
+	produce(ch)
+	_ = consume(ch, nil)
+	_ = pool.Run(2)
+}
@@ -0,0 +1,18 @@
+package pool
+
+func Run(n int) int {
+	results := make(chan int, n)
+
+	for i := 0; i < n; i++ {
+		results <- i
+	}
+
+	close(results)
+
+	total := 0
+	for r := range results {
+		total += r
+	}
+
+	return total
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type Store[K comparable, V any] struct {
+	path string
+	data map[K]V
+}
+
+func (s *Store[K, V]) Save() error {
+	return os.WriteFile(s.path, []byte(fmt.Sprint(s.data)), 0o600)
+}
+
+func Show[T any](v T) {
+	fmt.Println(v)
+}
+
+func Each[T any](xs []T, f func(T)) {
+	for _, x := range xs {
+		f(x)
+	}
+}
+
+func main() {
+	Show[int](1)
+	Each([]string{"a"}, Show[string])
+
+	s := &Store[string, int]{path: "store.json"}
+	_ = s.Save()
+}
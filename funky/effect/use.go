@@ -0,0 +1,211 @@
+package effect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "effect"
+
+// Enforce that Use implements the Finding type
+var _ finding.Finding = (*Use)(nil)
+
+// Use labels a call site (or a reference to a variable like os.Args) with the
+// effects the catalog attributes to the function or variable being used, or,
+// for functions the catalog doesn't know about, the effects of the functions
+// they call.
+type Use struct {
+	node    ast.Node
+	name    string
+	effects Set
+
+	// chains holds the calls leading to each effect, for effects that come
+	// from further down the call graph.
+	chains map[Kind][]string
+}
+
+func (u Use) Message(fset *token.FileSet) string {
+	return fmt.Sprintf("%s has effects: %s", u.subject(), Summary{Effects: u.effects, Chains: u.chains})
+}
+
+// subject describes what has the effects, e.g. "call to os.Open" or "send on
+// channel results".
+func (u Use) subject() string {
+	switch u.node.(type) {
+	case *ast.CallExpr:
+		return "call to " + u.name
+	case *ast.SendStmt:
+		return "send on channel " + u.name
+	case *ast.UnaryExpr:
+		return "receive from channel " + u.name
+	case *ast.RangeStmt:
+		return "range over channel " + u.name
+	case *ast.SelectStmt:
+		return "select statement"
+	}
+
+	return "use of " + u.name
+}
+
+func (u Use) Type() finding.Type {
+	return Type
+}
+
+func (u Use) Node() ast.Node {
+	return u.node
+}
+
+func (u Use) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(u.node.Pos()).String())
+}
+
+func (u Use) String() string {
+	return fmt.Sprintf("%s (%s)", u.name, u.effects)
+}
+
+// Effects returns the effects of the call or variable.
+func (u Use) Effects() Set {
+	return u.effects
+}
+
+// Name returns the catalog name of the function or variable being used.
+func (u Use) Name() string {
+	return u.name
+}
+
+// Chain returns the calls that lead to the effect of kind k, starting with the
+// function being called.
+func (u Use) Chain(k Kind) []string {
+	return u.chains[k]
+}
+
+// FindInFiles labels every call site and variable reference in the files of
+// pkg that the catalog attributes effects to. When g isn't nil, calls to other
+// functions are labeled with the effects of the functions they call in turn.
+func FindInFiles(files []*ast.File, pkg *types.Package, info *types.Info, c Catalog, g *Graph) []Use {
+	if info == nil {
+		return nil
+	}
+
+	var result []Use
+
+	channels := OfChannelOperation(c, pkg)
+
+	for _, file := range files {
+		// The sends and receives of a select statement's cases are reported as
+		// part of the select statement.
+		selected := make(map[ast.Node]struct{})
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			if _, ok := selected[node]; ok {
+				return true
+			}
+
+			switch n := node.(type) {
+			case *ast.SendStmt:
+				if len(channels) > 0 {
+					result = append(result, Use{node: n, name: types.ExprString(n.Chan), effects: channels})
+				}
+
+			case *ast.UnaryExpr:
+				if n.Op == token.ARROW && len(channels) > 0 {
+					result = append(result, Use{node: n, name: types.ExprString(n.X), effects: channels})
+				}
+
+			case *ast.RangeStmt:
+				if _, isChan := typeOf(info, n.X).(*types.Chan); isChan && len(channels) > 0 {
+					result = append(result, Use{node: n, name: types.ExprString(n.X), effects: channels})
+				}
+
+			case *ast.SelectStmt:
+				for _, stmt := range n.Body.List {
+					if comm := stmt.(*ast.CommClause).Comm; comm != nil {
+						markReceives(comm, selected)
+					}
+				}
+
+				if len(channels) > 0 {
+					result = append(result, Use{node: n, effects: channels})
+				}
+
+			case *ast.CallExpr:
+				if isBuiltin(info, n, "close") {
+					if len(channels) > 0 {
+						result = append(result, Use{node: n, name: "close", effects: channels})
+					}
+				} else if effects, name := OfCall(n, info, c); len(effects) > 0 {
+					result = append(result, Use{node: n, name: name, effects: effects})
+				} else if summary := g.OfCallSite(n); len(summary.Effects) > 0 {
+					result = append(result, Use{node: n, name: calleeName(n, info), effects: summary.Effects, chains: summary.Chains})
+				}
+
+			case *ast.Ident:
+				if v, ok := info.Uses[n].(*types.Var); ok && isPackageLevel(v) {
+					if effects := Lookup(c, v); len(effects) > 0 {
+						result = append(result, Use{node: n, name: ObjectName(v), effects: effects})
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(uses []Use) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, u := range uses {
+		findings = append(findings, u)
+	}
+
+	return findings
+}
+
+// markReceives adds the communication of a select case, along with the
+// receive operation inside it, to the set.
+func markReceives(comm ast.Stmt, set map[ast.Node]struct{}) {
+	set[comm] = struct{}{}
+
+	ast.Inspect(comm, func(node ast.Node) bool {
+		if unary, ok := node.(*ast.UnaryExpr); ok && unary.Op == token.ARROW {
+			set[unary] = struct{}{}
+			return false
+		}
+
+		return true
+	})
+}
+
+func isBuiltin(info *types.Info, call *ast.CallExpr, name string) bool {
+	builtin, ok := typeutil.Callee(info, call).(*types.Builtin)
+	return ok && builtin.Name() == name
+}
+
+func typeOf(info *types.Info, expr ast.Expr) types.Type {
+	t := info.TypeOf(expr)
+	if t == nil {
+		return nil
+	}
+
+	return t.Underlying()
+}
+
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	if obj := typeutil.Callee(info, call); obj != nil {
+		return ObjectName(obj)
+	}
+
+	return types.ExprString(call.Fun)
+}
+
+func isPackageLevel(v *types.Var) bool {
+	return v.Pkg() != nil && v.Parent() == v.Pkg().Scope()
+}
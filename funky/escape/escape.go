@@ -0,0 +1,324 @@
+package escape
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "escape-hatch"
+
+// Enforce that Hatch implements the Finding type
+var _ finding.Finding = (*Hatch)(nil)
+
+// setters lists the methods of reflect.Value that write to the value it refers to.
+var setters = map[string]struct{}{
+	"Set":          {},
+	"SetBool":      {},
+	"SetBytes":     {},
+	"SetCap":       {},
+	"SetComplex":   {},
+	"SetFloat":     {},
+	"SetInt":       {},
+	"SetIterKey":   {},
+	"SetIterValue": {},
+	"SetLen":       {},
+	"SetMapIndex":  {},
+	"SetPointer":   {},
+	"SetString":    {},
+	"SetUint":      {},
+	"SetZero":      {},
+	"Clear":        {},
+	"Grow":         {},
+}
+
+// Hatch describes a write made through reflection, or a conversion to
+// unsafe.Pointer, either of which can modify a variable without any of the
+// assignments that the other rules look for.
+type Hatch struct {
+	call *ast.CallExpr
+
+	// via is the function or method that makes the write (e.g.
+	// "(reflect.Value).SetInt" or "reflect.Copy"), or "unsafe.Pointer" for conversions.
+	via string
+
+	// variable is the variable the write or conversion was traced back to, if any.
+	variable *ast.Ident
+}
+
+func (h Hatch) Message(fset *token.FileSet) string {
+	if h.via == unsafePointer {
+		if h.variable == nil {
+			return fmt.Sprintf("%s is converted to unsafe.Pointer, which can be used to write to memory without any checks", funkyAST.Render(h.call.Args[0], fset))
+		}
+
+		return fmt.Sprintf("the address of %q is converted to unsafe.Pointer, which can be used to write to it without any checks", h.variable.Name)
+	}
+
+	if h.variable == nil {
+		return fmt.Sprintf("a value is written through reflection by %s", h.via)
+	}
+
+	return fmt.Sprintf("%q is written through reflection by %s", h.variable.Name, h.via)
+}
+
+func (h Hatch) Type() finding.Type {
+	return Type
+}
+
+func (h Hatch) Node() ast.Node {
+	return h.call
+}
+
+func (h Hatch) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(h.call.Pos()).String())
+}
+
+func (h Hatch) String() string {
+	return fmt.Sprintf("escape hatch via %s", h.via)
+}
+
+const unsafePointer = "unsafe.Pointer"
+
+// FindInFiles reports calls to the methods of reflect.Value that write to the
+// value, calls to reflect.Copy, and conversions to unsafe.Pointer. Each is
+// traced back to a variable when the reflect.Value or pointer comes from
+// taking the variable's address, e.g. `reflect.ValueOf(&x).Elem()`, either
+// directly or through local variables.
+func FindInFiles(files []*ast.File, info *types.Info) []Hatch {
+	if info == nil {
+		return nil
+	}
+
+	var result []Hatch
+
+	for _, file := range files {
+		t := tracer{info: info, values: valuesInFile(file, info)}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			if isUnsafePointerConversion(call, info) {
+				result = append(result, Hatch{call: call, via: unsafePointer, variable: t.addressed(call.Args[0], 0)})
+				return true
+			}
+
+			fn, ok := typeutil.Callee(info, call).(*types.Func)
+			if !ok {
+				return true
+			}
+
+			if _, isSetter := setters[fn.Name()]; isSetter && isReflectValueMethod(fn) {
+				selector := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+				result = append(result, Hatch{call: call, via: "(reflect.Value)." + fn.Name(), variable: t.reflected(selector.X, 0)})
+			}
+
+			if isReflectFunc(fn, "Copy") && len(call.Args) == 2 {
+				result = append(result, Hatch{call: call, via: "reflect.Copy", variable: t.reflected(call.Args[0], 0)})
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(hatches []Hatch) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, h := range hatches {
+		findings = append(findings, h)
+	}
+
+	return findings
+}
+
+// maxDepth limits how many local variables are followed when tracing a value
+// back to the variable it refers to.
+const maxDepth = 8
+
+// tracer follows reflect.Values and pointers back to the variables whose
+// addresses they were made from.
+type tracer struct {
+	info *types.Info
+
+	// values maps local variables to the single value they're assigned.
+	values map[types.Object]ast.Expr
+}
+
+// reflected traces a reflect.Value. Values derived from another by methods
+// like Elem, Field or Index refer to part of the same variable.
+func (t tracer) reflected(expr ast.Expr, depth int) *ast.Ident {
+	if depth > maxDepth {
+		return nil
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if value, ok := t.values[t.info.ObjectOf(e)]; ok {
+			return t.reflected(value, depth+1)
+		}
+
+	case *ast.CallExpr:
+		fn, ok := typeutil.Callee(t.info, e).(*types.Func)
+		if !ok {
+			return nil
+		}
+
+		if isReflectFunc(fn, "ValueOf") && len(e.Args) == 1 {
+			if ident := t.addressed(e.Args[0], depth); ident != nil {
+				return ident
+			}
+
+			// Slices, maps and pointers refer to the same memory as the variable
+			// holding them, so they don't need their address taken to be written to.
+			return t.reference(e.Args[0])
+		}
+
+		if isReflectValueMethod(fn) {
+			return t.reflected(ast.Unparen(e.Fun).(*ast.SelectorExpr).X, depth)
+		}
+	}
+
+	return nil
+}
+
+// addressed traces a pointer made by taking the address of a variable.
+func (t tracer) addressed(expr ast.Expr, depth int) *ast.Ident {
+	if depth > maxDepth {
+		return nil
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.UnaryExpr:
+		if e.Op != token.AND {
+			return nil
+		}
+
+		ident := funkyAST.RootIdentFromExpr(e.X)
+		if v, ok := t.info.ObjectOf(ident).(*types.Var); ok && !v.IsField() {
+			return ident
+		}
+
+	case *ast.Ident:
+		if value, ok := t.values[t.info.ObjectOf(e)]; ok {
+			return t.addressed(value, depth+1)
+		}
+	}
+
+	return nil
+}
+
+// reference returns the variable expr refers to, if it's a variable of a
+// slice, map or pointer type.
+func (t tracer) reference(expr ast.Expr) *ast.Ident {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	v, ok := t.info.ObjectOf(ident).(*types.Var)
+	if !ok || v.IsField() {
+		return nil
+	}
+
+	switch v.Type().Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Pointer:
+		return ident
+	}
+
+	return nil
+}
+
+// valuesInFile maps the variables in file that are assigned exactly one value,
+// by their declaration or by a single assignment, to that value.
+func valuesInFile(file *ast.File, info *types.Info) map[types.Object]ast.Expr {
+	values := make(map[types.Object]ast.Expr)
+	assignments := make(map[types.Object]int)
+
+	record := func(ident *ast.Ident, value ast.Expr) {
+		obj := info.ObjectOf(ident)
+		if obj == nil {
+			return
+		}
+
+		assignments[obj]++
+		values[obj] = value
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+
+				var value ast.Expr
+				if len(n.Lhs) == len(n.Rhs) {
+					value = n.Rhs[i]
+				}
+
+				record(ident, value)
+			}
+
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				var value ast.Expr
+				if len(n.Names) == len(n.Values) {
+					value = n.Values[i]
+				}
+
+				record(name, value)
+			}
+		}
+
+		return true
+	})
+
+	for obj, count := range assignments {
+		if count != 1 || values[obj] == nil {
+			delete(values, obj)
+		}
+	}
+
+	return values
+}
+
+func isReflectFunc(fn *types.Func, name string) bool {
+	return fn.Pkg() != nil && fn.Pkg().Path() == "reflect" && fn.Name() == name && fn.Signature().Recv() == nil
+}
+
+func isReflectValueMethod(fn *types.Func) bool {
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return false
+	}
+
+	t := recv.Type()
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "reflect" && named.Obj().Name() == "Value"
+}
+
+func isUnsafePointerConversion(call *ast.CallExpr, info *types.Info) bool {
+	if len(call.Args) != 1 || !info.Types[call.Fun].IsType() {
+		return false
+	}
+
+	basic, ok := info.TypeOf(call.Fun).(*types.Basic)
+	return ok && basic.Kind() == types.UnsafePointer
+}
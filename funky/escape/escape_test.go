@@ -0,0 +1,50 @@
+package escape
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableHatch struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableHatch{
+		{"testdata/hatches/main.go:15:2", `"c" is written through reflection by (reflect.Value).SetString`},
+		{"testdata/hatches/main.go:19:2", `"c" is written through reflection by (reflect.Value).SetInt`},
+		{"testdata/hatches/main.go:22:2", `"names" is written through reflection by reflect.Copy`},
+		{"testdata/hatches/main.go:25:16", `the address of "n" is converted to unsafe.Pointer, which can be used to write to it without any checks`},
+		{"testdata/hatches/main.go:34:2", "a value is written through reflection by (reflect.Value).SetInt"},
+		{"testdata/hatches/main.go:36:7", "uintptr(0) is converted to unsafe.Pointer, which can be used to write to memory without any checks"},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "hatches/main.go")
+
+	hatches := FindInFiles([]*ast.File{file}, info)
+
+	if len(hatches) != len(expected) {
+		for _, h := range hatches {
+			t.Log(h.Location(fset), h.Message(fset))
+		}
+
+		t.Fatalf("expected %d escape hatches, but found %d", len(expected), len(hatches))
+	}
+
+	for i, h := range hatches {
+		actual := testableHatch{
+			location: h.Location(fset),
+			message:  h.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type config struct {
+	Name  string
+	Count int
+}
+
+func main() {
+	var c config
+	reflect.ValueOf(&c).Elem().Field(0).SetString("x")
+
+	v := reflect.ValueOf(&c).Elem()
+	count := v.FieldByName("Count")
+	count.SetInt(2)
+
+	names := []string{"a"}
+	reflect.Copy(reflect.ValueOf(names), reflect.ValueOf([]string{"b"}))
+
+	n := 1
+	p := (*int64)(unsafe.Pointer(&n))
+	*p = 2
+
+	set(reflect.ValueOf(&n).Elem())
+
+	_ = reflect.ValueOf(c).Field(0).String()
+}
+
+func set(v reflect.Value) {
+	v.SetInt(3)
+
+	q := unsafe.Pointer(uintptr(0))
+	_ = q
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
 )
 
 type Type string
@@ -16,6 +19,59 @@ type Finding interface {
 	Message(*token.FileSet) string
 }
 
+// Fixer is implemented by findings that can suggest source edits that resolve them.
+type Fixer interface {
+	SuggestedFixes(*token.FileSet) []analysis.SuggestedFix
+}
+
+// Aggregate is implemented by findings that describe a higher-level pattern
+// made up of nodes that would otherwise be reported individually. Only the
+// findings of the subsumed types are replaced on those nodes; findings of
+// other types on the same nodes are still reported.
+type Aggregate interface {
+	Subsumes() []ast.Node
+	SubsumedTypes() []Type
+}
+
 func Report(f Finding, fset *token.FileSet) string {
 	return fmt.Sprintf("%s: %s: %s", f.Location(fset), f.Type(), f.Message(fset))
 }
+
+type subsumption struct {
+	node ast.Node
+	t    Type
+}
+
+// Consolidate drops findings whose node and type are already covered by an
+// Aggregate finding, and orders the remaining findings by position.
+func Consolidate(findings []Finding) []Finding {
+	subsumed := make(map[subsumption]struct{})
+
+	for _, f := range findings {
+		if aggregate, ok := f.(Aggregate); ok {
+			for _, node := range aggregate.Subsumes() {
+				for _, t := range aggregate.SubsumedTypes() {
+					subsumed[subsumption{node: node, t: t}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var result []Finding
+
+	for _, f := range findings {
+		if _, ok := f.(Aggregate); !ok {
+			if _, isSubsumed := subsumed[subsumption{node: f.Node(), t: f.Type()}]; isSubsumed {
+				continue
+			}
+		}
+
+		result = append(result, f)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Node().Pos() < result[j].Node().Pos()
+	})
+
+	return result
+}
@@ -0,0 +1,352 @@
+package immutable
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"golang.org/x/tools/go/packages"
+)
+
+var Type finding.Type = "immutable-write"
+
+// Directive is the comment that marks a struct type as immutable.
+const Directive = "//funky:immutable"
+
+// Enforce that Write implements the Finding and Aggregate types
+var (
+	_ finding.Finding   = (*Write)(nil)
+	_ finding.Aggregate = (*Write)(nil)
+)
+
+// Write describes an assignment to a field of a value whose type is marked
+// with //funky:immutable, or to an element of a slice, map or array field of
+// such a value, made outside of one of the type's constructors.
+type Write struct {
+	stmt ast.Stmt
+
+	owner *types.TypeName
+	field string
+
+	// element is true for writes to an element of the field rather than to the field itself.
+	element bool
+
+	// method is set when the write is made by a pointer-receiver method of the
+	// immutable type itself.
+	method *types.Func
+
+	// pkg is the package the write is made in, used to qualify type names.
+	pkg *types.Package
+}
+
+func (w Write) Message(fset *token.FileSet) string {
+	target := fmt.Sprintf("field %s", w.field)
+	if w.element {
+		target = fmt.Sprintf("an element of field %s", w.field)
+	}
+
+	if w.method != nil {
+		return fmt.Sprintf("method %s writes to %s of its immutable receiver; return a new %s instead", w.methodName(), target, w.typeName())
+	}
+
+	return fmt.Sprintf("%s of immutable type %s is written to outside of a constructor", target, w.typeName())
+}
+
+func (w Write) Type() finding.Type {
+	return Type
+}
+
+func (w Write) Node() ast.Node {
+	return w.stmt
+}
+
+func (w Write) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(w.stmt.Pos()).String())
+}
+
+func (w Write) String() string {
+	return fmt.Sprintf("write to immutable %s.%s", w.typeName(), w.field)
+}
+
+// Subsumes returns the write itself, which would otherwise also be reported as a mutation.
+func (w Write) Subsumes() []ast.Node {
+	return []ast.Node{w.stmt}
+}
+
+// SubsumedTypes returns the mutation types.
+func (w Write) SubsumedTypes() []finding.Type {
+	return mutation.Types
+}
+
+func (w Write) typeName() string {
+	return types.TypeString(w.owner.Type(), func(p *types.Package) string {
+		if p == w.pkg {
+			return ""
+		}
+
+		return p.Name()
+	})
+}
+
+func (w Write) methodName() string {
+	return fmt.Sprintf("(*%s).%s", w.typeName(), w.method.Name())
+}
+
+// Types returns the types declared in the packages that are marked as immutable.
+func Types(pkgs []*packages.Package) map[*types.TypeName]struct{} {
+	result := make(map[*types.TypeName]struct{})
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+
+					// A directive on the declaration applies to a lone type spec, as in `type T struct{...}`.
+					if !hasDirective(typeSpec.Doc) && !(len(genDecl.Specs) == 1 && hasDirective(genDecl.Doc)) {
+						continue
+					}
+
+					if obj, ok := pkg.TypesInfo.Defs[typeSpec.Name].(*types.TypeName); ok {
+						if _, isStruct := obj.Type().Underlying().(*types.Struct); isStruct {
+							result[obj] = struct{}{}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// FindInPackages reports writes to values of the immutable types declared in
+// any of the packages.
+func FindInPackages(pkgs []*packages.Package, c config.Config) []Write {
+	immutables := Types(pkgs)
+	if len(immutables) == 0 {
+		return nil
+	}
+
+	var result []Write
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		check := checker{
+			info:         pkg.TypesInfo,
+			pkg:          pkg.Types,
+			immutables:   immutables,
+			constructors: c.Immutable.Constructors,
+		}
+
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+					result = append(result, check.funcDecl(funcDecl)...)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func Findings(writes []Write) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, w := range writes {
+		findings = append(findings, w)
+	}
+
+	return findings
+}
+
+type checker struct {
+	info         *types.Info
+	pkg          *types.Package
+	immutables   map[*types.TypeName]struct{}
+	constructors []string
+}
+
+func (c checker) funcDecl(decl *ast.FuncDecl) []Write {
+	var result []Write
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		var targets []ast.Expr
+
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				targets = n.Lhs
+			}
+
+		case *ast.IncDecStmt:
+			targets = []ast.Expr{n.X}
+		}
+
+		for _, target := range targets {
+			w, ok := c.write(target)
+			if !ok || c.isConstructorOf(decl, w.owner) {
+				continue
+			}
+
+			w.stmt = node.(ast.Stmt)
+			w.method = c.pointerMethodOf(decl, w.owner)
+			result = append(result, w)
+
+			break
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// write resolves the immutable value, if any, that writing to expr changes.
+// Writes through a pointer field only change the value being pointed to, so
+// they don't count as writes to the value holding the pointer.
+func (c checker) write(expr ast.Expr) (Write, bool) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.SelectorExpr:
+		selection := c.info.Selections[e]
+		if selection == nil || selection.Kind() != types.FieldVal {
+			return Write{}, false
+		}
+
+		if owner := fieldOwner(selection); c.isImmutable(owner) {
+			return Write{owner: owner, field: e.Sel.Name, pkg: c.pkg}, true
+		}
+
+		if _, isPointer := selection.Recv().Underlying().(*types.Pointer); isPointer || selection.Indirect() {
+			return Write{}, false
+		}
+
+		return c.write(e.X)
+
+	case *ast.IndexExpr:
+		t := c.info.TypeOf(e.X)
+		if t == nil {
+			return Write{}, false
+		}
+
+		switch t.Underlying().(type) {
+		case *types.Array, *types.Slice, *types.Map:
+			w, ok := c.write(e.X)
+			if ok {
+				w.element = true
+			}
+
+			return w, ok
+		}
+	}
+
+	return Write{}, false
+}
+
+func (c checker) isImmutable(owner *types.TypeName) bool {
+	if owner == nil {
+		return false
+	}
+
+	_, ok := c.immutables[owner]
+	return ok
+}
+
+// isConstructorOf reports whether decl is one of the functions allowed to
+// write to the type: a function (not a method) in the type's package whose
+// name starts with one of the configured prefixes.
+func (c checker) isConstructorOf(decl *ast.FuncDecl, owner *types.TypeName) bool {
+	if decl.Recv != nil || owner.Pkg() != c.pkg {
+		return false
+	}
+
+	for _, prefix := range c.constructors {
+		if strings.HasPrefix(decl.Name.Name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pointerMethodOf returns the method declared by decl if it has a pointer
+// receiver of the owner type.
+func (c checker) pointerMethodOf(decl *ast.FuncDecl, owner *types.TypeName) *types.Func {
+	fn, ok := c.info.Defs[decl.Name].(*types.Func)
+	if !ok || fn.Signature().Recv() == nil {
+		return nil
+	}
+
+	pointer, ok := fn.Signature().Recv().Type().(*types.Pointer)
+	if !ok {
+		return nil
+	}
+
+	if named, ok := pointer.Elem().(*types.Named); ok && named.Origin().Obj() == owner {
+		return fn
+	}
+
+	return nil
+}
+
+// fieldOwner returns the named struct type that declares the selected field,
+// following any embedded fields along the way.
+func fieldOwner(selection *types.Selection) *types.TypeName {
+	t := selection.Recv()
+	indexes := selection.Index()
+
+	for _, i := range indexes[:len(indexes)-1] {
+		s, ok := deref(t).Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+
+		t = s.Field(i).Type()
+	}
+
+	if named, ok := deref(t).(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+
+	return nil
+}
+
+func deref(t types.Type) types.Type {
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		return pointer.Elem()
+	}
+
+	return t
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, comment := range doc.List {
+		if strings.TrimSpace(comment.Text) == Directive {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package immutable
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInPackages(t *testing.T) {
+	type testableWrite struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableWrite{
+		{"testdata/values/values.go:31:2", "method (*Config).Rename writes to field Name of its immutable receiver; return a new Config instead"},
+		{"testdata/values/values.go:39:2", "an element of field Tags of immutable type Config is written to outside of a constructor"},
+		{"testdata/values/values.go:40:2", "an element of field Limits of immutable type Config is written to outside of a constructor"},
+		{"testdata/values/values.go:41:2", "an element of field Retries of immutable type Config is written to outside of a constructor"},
+		{"testdata/values/values.go:42:2", "field Inner of immutable type Config is written to outside of a constructor"},
+		{"testdata/values/app/main.go:7:2", "field Name of immutable type values.Config is written to outside of a constructor"},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "values")
+
+	writes := FindInPackages(pkgs, config.Default())
+
+	if len(writes) != len(expected) {
+		for _, w := range writes {
+			t.Log(fixture.RelativeLocation(t, w.Location(fset)), w.Message(fset))
+		}
+
+		t.Fatalf("expected %d writes, but found %d", len(expected), len(writes))
+	}
+
+	for i, w := range writes {
+		actual := testableWrite{
+			location: fixture.RelativeLocation(t, w.Location(fset)),
+			message:  w.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
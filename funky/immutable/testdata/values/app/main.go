@@ -0,0 +1,9 @@
+package main
+
+import "example.com/values"
+
+func main() {
+	c := values.NewConfig("a")
+	c.Name = "b"
+	values.Tag(c, "x")
+}
@@ -0,0 +1,48 @@
+package values
+
+// Config is shared between goroutines, so it must not change once it's built.
+//
+//funky:immutable
+type Config struct {
+	Name    string
+	Tags    []string
+	Limits  map[string]int
+	Retries [3]int
+	Inner   Inner
+	Next    *Inner
+}
+
+type Inner struct {
+	Enabled bool
+}
+
+type Mutable struct {
+	Count int
+}
+
+func NewConfig(name string) *Config {
+	c := &Config{}
+	c.Name = name
+	c.Limits = map[string]int{}
+	return c
+}
+
+func (c *Config) Rename(name string) {
+	c.Name = name
+}
+
+func (c Config) WithName(name string) Config {
+	return Config{Name: name, Tags: c.Tags}
+}
+
+func Tag(c *Config, tag string) {
+	c.Tags[0] = tag
+	c.Limits[tag]++
+	c.Retries[1] = 2
+	c.Inner.Enabled = true
+	c.Next.Enabled = true
+}
+
+func Count(m *Mutable) {
+	m.Count++
+}
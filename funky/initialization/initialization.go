@@ -0,0 +1,357 @@
+package initialization
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"golang.org/x/tools/go/analysis"
+)
+
+var Type finding.Type = "deferred-initialization"
+
+// Enforce that DeferredInitialization implements the Finding, Fixer and Aggregate types
+var (
+	_ finding.Finding   = (*DeferredInitialization)(nil)
+	_ finding.Fixer     = (*DeferredInitialization)(nil)
+	_ finding.Aggregate = (*DeferredInitialization)(nil)
+)
+
+// DeferredInitialization describes a variable that is declared without a value
+// and then assigned exactly once on every path of the statement that follows,
+// e.g. `var x T; if cond { x = a } else { x = b }`.
+type DeferredInitialization struct {
+	decl        *ast.DeclStmt
+	spec        *ast.ValueSpec
+	branching   ast.Stmt
+	assignments []*ast.AssignStmt
+}
+
+func (d DeferredInitialization) Message(fset *token.FileSet) string {
+	return fmt.Sprintf(
+		"%q is declared without a value and then assigned on every path of the %s that follows; initialize it once using a helper function or an immediately-invoked closure",
+		varName(d),
+		statementKind(d.branching),
+	)
+}
+
+func (d DeferredInitialization) Type() finding.Type {
+	return Type
+}
+
+func (d DeferredInitialization) Node() ast.Node {
+	return d.decl
+}
+
+func (d DeferredInitialization) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(d.decl.Pos()).String())
+}
+
+func (d DeferredInitialization) String() string {
+	return fmt.Sprintf("%q initialized by %s", varName(d), statementKind(d.branching))
+}
+
+func (d DeferredInitialization) Subsumes() []ast.Node {
+	var nodes []ast.Node
+
+	for _, a := range d.assignments {
+		nodes = append(nodes, a)
+	}
+
+	return nodes
+}
+
+// SubsumedTypes returns the mutation types, since each assignment is otherwise
+// reported as a mutation of the variable.
+func (d DeferredInitialization) SubsumedTypes() []finding.Type {
+	return mutation.Types
+}
+
+// SuggestedFixes turns the branching statement into the body of an
+// immediately-invoked closure that returns the value for each path.
+func (d DeferredInitialization) SuggestedFixes(fset *token.FileSet) []analysis.SuggestedFix {
+	edits := []analysis.TextEdit{
+		{
+			Pos:     d.decl.Pos(),
+			End:     d.decl.End(),
+			NewText: []byte(fmt.Sprintf("%s := func() %s {", varName(d), funkyAST.Render(d.spec.Type, fset))),
+		},
+	}
+
+	for _, a := range d.assignments {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     a.Pos(),
+			End:     a.Rhs[0].Pos(),
+			NewText: []byte("return "),
+		})
+	}
+
+	edits = append(edits, analysis.TextEdit{
+		Pos:     d.branching.End(),
+		End:     d.branching.End(),
+		NewText: []byte("\n}()"),
+	})
+
+	return []analysis.SuggestedFix{
+		{
+			Message:   fmt.Sprintf("Initialize %q with an immediately-invoked closure", varName(d)),
+			TextEdits: edits,
+		},
+	}
+}
+
+func FindInFiles(files []*ast.File) []DeferredInitialization {
+	var result []DeferredInitialization
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if list := stmtList(node); list != nil {
+				result = append(result, findInStmtList(list)...)
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(initializations []DeferredInitialization) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, d := range initializations {
+		findings = append(findings, d)
+	}
+
+	return findings
+}
+
+func findInStmtList(list []ast.Stmt) []DeferredInitialization {
+	var result []DeferredInitialization
+
+	for i := 0; i+1 < len(list); i++ {
+		if d, ok := deferredInitialization(list[i], list[i+1]); ok {
+			result = append(result, d)
+		}
+	}
+
+	return result
+}
+
+func deferredInitialization(stmt, next ast.Stmt) (DeferredInitialization, bool) {
+	decl, spec := uninitializedVarDecl(stmt)
+	if spec == nil {
+		return DeferredInitialization{}, false
+	}
+
+	name := spec.Names[0].Name
+
+	branches := branchesOf(next)
+	if branches == nil {
+		return DeferredInitialization{}, false
+	}
+
+	var assignments []*ast.AssignStmt
+
+	for _, branch := range branches {
+		a := finalAssignment(branch, name)
+		if a == nil {
+			return DeferredInitialization{}, false
+		}
+
+		assignments = append(assignments, a)
+	}
+
+	// The variable must not be read or written anywhere else in the statement,
+	// and control must not leave the statement early.
+	if countIdents(next, name) != len(assignments) || leavesEarly(next) {
+		return DeferredInitialization{}, false
+	}
+
+	return DeferredInitialization{
+		decl:        decl,
+		spec:        spec,
+		branching:   next,
+		assignments: assignments,
+	}, true
+}
+
+// uninitializedVarDecl matches a statement of the form `var x T`.
+func uninitializedVarDecl(stmt ast.Stmt) (*ast.DeclStmt, *ast.ValueSpec) {
+	decl, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return nil, nil
+	}
+
+	genDecl, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+		return nil, nil
+	}
+
+	spec, ok := genDecl.Specs[0].(*ast.ValueSpec)
+	if !ok || len(spec.Names) != 1 || spec.Type == nil || len(spec.Values) != 0 {
+		return nil, nil
+	}
+
+	if funkyAST.BlankIdentifier(spec.Names[0]) {
+		return nil, nil
+	}
+
+	return decl, spec
+}
+
+// branchesOf returns the statement lists of every path through an if/else
+// chain or switch statement, or nil if some path doesn't go through a branch
+// (e.g. an if without an else, or a switch without a default clause).
+func branchesOf(stmt ast.Stmt) [][]ast.Stmt {
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		return ifBranches(s)
+
+	case *ast.SwitchStmt:
+		return caseBranches(s.Body)
+
+	case *ast.TypeSwitchStmt:
+		return caseBranches(s.Body)
+	}
+
+	return nil
+}
+
+func ifBranches(ifStmt *ast.IfStmt) [][]ast.Stmt {
+	branches := [][]ast.Stmt{ifStmt.Body.List}
+
+	switch e := ifStmt.Else.(type) {
+	case *ast.BlockStmt:
+		return append(branches, e.List)
+
+	case *ast.IfStmt:
+		elseBranches := ifBranches(e)
+		if elseBranches == nil {
+			return nil
+		}
+
+		return append(branches, elseBranches...)
+	}
+
+	return nil
+}
+
+func caseBranches(body *ast.BlockStmt) [][]ast.Stmt {
+	if body == nil {
+		return nil
+	}
+
+	var branches [][]ast.Stmt
+	hasDefault := false
+
+	for _, stmt := range body.List {
+		clause, ok := stmt.(*ast.CaseClause)
+		if !ok {
+			return nil
+		}
+
+		if clause.List == nil {
+			hasDefault = true
+		}
+
+		branches = append(branches, clause.Body)
+	}
+
+	if !hasDefault {
+		return nil
+	}
+
+	return branches
+}
+
+// finalAssignment returns the branch's last statement if it is a plain
+// assignment of a single value to the named variable.
+func finalAssignment(branch []ast.Stmt, name string) *ast.AssignStmt {
+	if len(branch) == 0 {
+		return nil
+	}
+
+	a, ok := branch[len(branch)-1].(*ast.AssignStmt)
+	if !ok || a.Tok != token.ASSIGN || len(a.Lhs) != 1 || len(a.Rhs) != 1 {
+		return nil
+	}
+
+	if ident := funkyAST.IdentFromExpr(a.Lhs[0]); ident == nil || ident.Name != name {
+		return nil
+	}
+
+	return a
+}
+
+func countIdents(node ast.Node, name string) int {
+	count := 0
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+// leavesEarly reports whether the statement contains a return or branch
+// statement (outside of function literals), which would change meaning once
+// the statement is moved into a closure.
+func leavesEarly(node ast.Node) bool {
+	found := false
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+
+		case *ast.ReturnStmt, *ast.BranchStmt:
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func stmtList(node ast.Node) []ast.Stmt {
+	switch n := node.(type) {
+	case *ast.BlockStmt:
+		return n.List
+
+	case *ast.CaseClause:
+		return n.Body
+
+	case *ast.CommClause:
+		return n.Body
+	}
+
+	return nil
+}
+
+func statementKind(stmt ast.Stmt) string {
+	switch stmt.(type) {
+	case *ast.IfStmt:
+		return "if statement"
+
+	case *ast.SwitchStmt:
+		return "switch statement"
+
+	case *ast.TypeSwitchStmt:
+		return "type switch statement"
+	}
+
+	return "statement"
+}
+
+func varName(d DeferredInitialization) string {
+	return d.spec.Names[0].Name
+}
@@ -0,0 +1,117 @@
+package initialization
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+)
+
+func TestFindInFiles(t *testing.T) {
+	expected := []finding.Location{
+		"testdata/deferred/main.go:6:2",
+		"testdata/deferred/main.go:16:2",
+		"testdata/deferred/main.go:29:2",
+	}
+
+	fset := token.NewFileSet()
+	file := loadGoSourceTestFixture(t, fset, "deferred/main.go")
+
+	initializations := FindInFiles([]*ast.File{file})
+
+	if len(initializations) != len(expected) {
+		t.Fatalf("expected %d deferred initializations, but found %d", len(expected), len(initializations))
+	}
+
+	for i, d := range initializations {
+		if actual := d.Location(fset); actual != expected[i] {
+			t.Errorf("expected deferred initialization at %s, but found it at %s", expected[i], actual)
+		}
+	}
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	const src = `package p
+
+func f(ok bool) {
+	var x string
+	if ok {
+		x = "yes"
+	} else {
+		x = "no"
+	}
+	print(x)
+}
+`
+
+	const expected = `package p
+
+func f(ok bool) {
+	x := func() string {
+		if ok {
+			return "yes"
+		} else {
+			return "no"
+		}
+	}()
+	print(x)
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	initializations := FindInFiles([]*ast.File{file})
+	if len(initializations) != 1 {
+		t.Fatalf("expected 1 deferred initialization, but found %d", len(initializations))
+	}
+
+	fixes := initializations[0].SuggestedFixes(fset)
+	if len(fixes) != 1 {
+		t.Fatalf("expected 1 suggested fix, but found %d", len(fixes))
+	}
+
+	edits := fixes[0].TextEdits
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Pos < edits[j].Pos
+	})
+
+	var fixed []byte
+	offset := 0
+
+	for _, edit := range edits {
+		start := fset.Position(edit.Pos).Offset
+		fixed = append(fixed, src[offset:start]...)
+		fixed = append(fixed, edit.NewText...)
+		offset = fset.Position(edit.End).Offset
+	}
+
+	fixed = append(fixed, src[offset:]...)
+
+	formatted, err := format.Source(fixed)
+	if err != nil {
+		t.Fatalf("unable to format fixed source: %v\n%s", err, fixed)
+	}
+
+	if actual := string(formatted); actual != expected {
+		t.Errorf("unexpected fixed source:\n%s", actual)
+	}
+}
+
+func loadGoSourceTestFixture(t testing.TB, fset *token.FileSet, fixtureFile string) *ast.File {
+	t.Helper()
+
+	file, err := parser.ParseFile(fset, "testdata/"+fixtureFile, nil, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("unable to load Go source test fixture: %v", err)
+	}
+
+	return file
+}
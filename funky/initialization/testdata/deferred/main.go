@@ -0,0 +1,101 @@
+package main
+
+import "strings"
+
+func ifElse(s string) {
+	var x string // deferred initialization
+	if strings.HasPrefix(s, "a") {
+		x = "a"
+	} else {
+		x = "b"
+	}
+	print(x)
+}
+
+func ifElseIfElse(n int) {
+	var size string // deferred initialization
+	if n < 10 {
+		size = "small"
+	} else if n < 100 {
+		print(n)
+		size = "medium"
+	} else {
+		size = "large"
+	}
+	print(size)
+}
+
+func switchWithDefault(n int) {
+	var label string // deferred initialization
+	switch n {
+	case 1:
+		label = "one"
+	default:
+		label = "many"
+	}
+	print(label)
+}
+
+func ifWithoutElse(ok bool) {
+	var x string // not deferred initialization: not assigned on every path
+	if ok {
+		x = "ok"
+	}
+	print(x)
+}
+
+func switchWithoutDefault(n int) {
+	var x string // not deferred initialization: not assigned on every path
+	switch n {
+	case 1:
+		x = "one"
+	}
+	print(x)
+}
+
+func readBeforeAssigning(ok bool) {
+	var x string // not deferred initialization: x is read inside the branch
+	if ok {
+		x = x + "ok"
+	} else {
+		x = "not ok"
+	}
+	print(x)
+}
+
+func assignmentNotLast(ok bool) {
+	var x string // not deferred initialization: x is assigned before the branch ends
+	if ok {
+		x = "ok"
+		print(ok)
+	} else {
+		x = "not ok"
+	}
+	print(x)
+}
+
+func returnInBranch(ok bool) {
+	var x string // not deferred initialization: a branch returns early
+	if ok {
+		if len(x) > 0 {
+			return
+		}
+		x = "ok"
+	} else {
+		x = "not ok"
+	}
+	print(x)
+}
+
+func statementInBetween(ok bool) {
+	var x string // not deferred initialization: a statement separates the declaration
+	print(ok)
+	if ok {
+		x = "ok"
+	} else {
+		x = "not ok"
+	}
+	print(x)
+}
+
+func main() {}
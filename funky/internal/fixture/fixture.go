@@ -0,0 +1,88 @@
+package fixture
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/packages"
+)
+
+// Packages loads the packages of the test fixture module in testdata, sorted
+// by import path.
+func Packages(t testing.TB, fset *token.FileSet, fixtureModule string) []*packages.Package {
+	t.Helper()
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  "testdata/" + fixtureModule,
+		Fset: fset,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("unable to load test fixture packages: %v", err)
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatalf("test fixture packages have errors")
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].PkgPath < pkgs[j].PkgPath
+	})
+
+	return pkgs
+}
+
+// TypedFile parses and type-checks a single test fixture file in testdata as
+// package main.
+func TypedFile(t testing.TB, fset *token.FileSet, fixtureFile string) (*ast.File, *types.Info) {
+	t.Helper()
+	const errMessage = "unable to load Go source test fixture: %v"
+
+	file, err := parser.ParseFile(fset, "testdata/"+fixtureFile, nil, parser.AllErrors)
+	if err != nil {
+		t.Fatalf(errMessage, err)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("main", fset, []*ast.File{file}, info); err != nil {
+		t.Fatalf(errMessage, err)
+	}
+
+	return file, info
+}
+
+// RelativeLocation makes a location loaded from a fixture module relative to
+// the test's working directory, so it can be compared with expected locations.
+func RelativeLocation(t testing.TB, location finding.Location) finding.Location {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unable to get working directory: %v", err)
+	}
+
+	rel, err := filepath.Rel(wd, string(location))
+	if err != nil {
+		return location
+	}
+
+	return finding.Location(rel)
+}
@@ -0,0 +1,198 @@
+package mutation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// AddressTakenType is the type of address-of expressions that let a variable
+// be modified from somewhere else.
+var AddressTakenType finding.Type = "address-taken"
+
+// Enforce that AddressTaken implements the Finding type
+var _ finding.Finding = (*AddressTaken)(nil)
+
+// AddressTaken describes a variable whose address is passed to a function, as
+// in `json.Unmarshal(data, &x)`, or stored, as in `p := &x`. Whatever receives
+// the address can modify the variable.
+type AddressTaken struct {
+	unary    *ast.UnaryExpr
+	variable *ast.Ident
+
+	// callee is set when the address is passed to a function, and destination
+	// is set when it's stored.
+	callee      string
+	destination ast.Expr
+
+	// field is true when the destination is a field of a composite literal.
+	field bool
+}
+
+func (a AddressTaken) Message(fset *token.FileSet) string {
+	prefix := fmt.Sprintf("%q may be modified through its address", a.variable.Name)
+
+	if a.field {
+		return fmt.Sprintf("%s, which is stored in field %s", prefix, funkyAST.Render(a.destination, fset))
+	}
+
+	if a.destination != nil {
+		return fmt.Sprintf("%s, which is stored in %s", prefix, funkyAST.Render(a.destination, fset))
+	}
+
+	return fmt.Sprintf("%s, which is passed to %s", prefix, a.callee)
+}
+
+func (a AddressTaken) Type() finding.Type {
+	return AddressTakenType
+}
+
+func (a AddressTaken) Node() ast.Node {
+	return a.unary
+}
+
+func (a AddressTaken) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(a.unary.Pos()).String())
+}
+
+func (a AddressTaken) String() string {
+	return fmt.Sprintf("address of %q taken", a.variable.Name)
+}
+
+// FindAddressesTakenInFiles reports the addresses of variables that are passed
+// as arguments to calls or stored in other variables, fields or composite
+// literals. Passing an address to a function or method declared in the files,
+// or in the packages facts were recorded for, isn't reported when the function
+// doesn't write through that parameter. facts may be nil.
+func FindAddressesTakenInFiles(files []*ast.File, info *types.Info, facts *WriteFacts) []AddressTaken {
+	if info == nil {
+		return nil
+	}
+
+	facts = writeFactsOf(facts, files, info)
+
+	var result []AddressTaken
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				if info.Types[n.Fun].IsType() {
+					break
+				}
+
+				callee := typeutil.Callee(info, n)
+
+				for i, arg := range n.Args {
+					unary, variable, ok := addressOfVariable(arg, info)
+					if !ok {
+						continue
+					}
+
+					if fn, ok := callee.(*types.Func); ok && !facts.writesThroughParameter(fn, i) {
+						continue
+					}
+
+					result = append(result, AddressTaken{unary: unary, variable: variable, callee: calleeName(n, callee)})
+				}
+
+			case *ast.AssignStmt:
+				if len(n.Lhs) != len(n.Rhs) {
+					break
+				}
+
+				for i, rhs := range n.Rhs {
+					if unary, variable, ok := addressOfVariable(rhs, info); ok && !funkyAST.BlankIdentifier(funkyAST.IdentFromExpr(n.Lhs[i])) {
+						result = append(result, AddressTaken{unary: unary, variable: variable, destination: n.Lhs[i]})
+					}
+				}
+
+			case *ast.ValueSpec:
+				for i, value := range n.Values {
+					if i >= len(n.Names) || funkyAST.BlankIdentifier(n.Names[i]) {
+						continue
+					}
+
+					if unary, variable, ok := addressOfVariable(value, info); ok {
+						result = append(result, AddressTaken{unary: unary, variable: variable, destination: n.Names[i]})
+					}
+				}
+
+			case *ast.CompositeLit:
+				for _, elt := range n.Elts {
+					a := AddressTaken{destination: n.Type}
+
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						elt = kv.Value
+						a.destination = kv.Key
+						a.field = isStructType(info.TypeOf(n))
+					}
+
+					unary, variable, ok := addressOfVariable(elt, info)
+					if !ok || a.destination == nil {
+						continue
+					}
+
+					a.unary, a.variable = unary, variable
+					result = append(result, a)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func AddressTakenFindings(addresses []AddressTaken) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, a := range addresses {
+		findings = append(findings, a)
+	}
+
+	return findings
+}
+
+// addressOfVariable matches `&x`, or the address of part of x like `&x.field`,
+// where x is a variable.
+func addressOfVariable(expr ast.Expr, info *types.Info) (*ast.UnaryExpr, *ast.Ident, bool) {
+	unary, ok := ast.Unparen(expr).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return nil, nil, false
+	}
+
+	ident := funkyAST.RootIdentFromExpr(unary.X)
+	if ident == nil || funkyAST.BlankIdentifier(ident) {
+		return nil, nil, false
+	}
+
+	if v, ok := info.ObjectOf(ident).(*types.Var); !ok || v.IsField() {
+		return nil, nil, false
+	}
+
+	return unary, ident, true
+}
+
+func isStructType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+func calleeName(call *ast.CallExpr, callee types.Object) string {
+	if fn, ok := callee.(*types.Func); ok {
+		return fn.FullName()
+	}
+
+	return types.ExprString(call.Fun)
+}
@@ -0,0 +1,225 @@
+package mutation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/scope"
+)
+
+// AppendAliasType is the type of appends whose result may share a backing
+// array with a slice that's still in use elsewhere.
+var AppendAliasType finding.Type = "append-alias"
+
+// Enforce that AppendAlias implements the Finding type
+var _ finding.Finding = (*AppendAlias)(nil)
+
+// AppendAlias describes a call to append that can write into the backing array
+// of a slice other than the one it's assigned to. When the first argument has
+// spare capacity, append doesn't allocate, so `b := append(a, x)` changes
+// a[:cap(a)] as well, and appending to a parameter changes the caller's array.
+type AppendAlias struct {
+	call *ast.CallExpr
+
+	// source is the slice being appended to.
+	source ast.Expr
+
+	// target is the variable the result is assigned to, unless source is a parameter.
+	target ast.Expr
+
+	// parameter is set when source is (a slice of) a parameter.
+	parameter *ast.Ident
+}
+
+func (a AppendAlias) Message(fset *token.FileSet) string {
+	source := funkyAST.Render(a.source, fset)
+	fix := fmt.Sprintf("copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: %s", fullSliceExpr(a.source, fset))
+
+	if a.parameter != nil {
+		return fmt.Sprintf("append to parameter %q may write into the caller's backing array when it has spare capacity; %s", a.parameter.Name, fix)
+	}
+
+	return fmt.Sprintf("%q is assigned %s, which may write into the backing array of %q when it has spare capacity; %s", funkyAST.Render(a.target, fset), funkyAST.Render(a.call, fset), source, fix)
+}
+
+func (a AppendAlias) Type() finding.Type {
+	return AppendAliasType
+}
+
+func (a AppendAlias) Node() ast.Node {
+	return a.call
+}
+
+func (a AppendAlias) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(a.call.Pos()).String())
+}
+
+func (a AppendAlias) String() string {
+	return fmt.Sprintf("append to %s aliased", types.ExprString(a.source))
+}
+
+// FindAppendAliasesInFiles reports appends to parameters, and appends whose
+// result is assigned to a different variable than the slice being appended to.
+func FindAppendAliasesInFiles(files []*ast.File) []AppendAlias {
+	var result []AppendAlias
+
+	packageScope := scope.FromFiles(files)
+
+	for _, file := range files {
+		funkyAST.InspectWithInitialScope(file, func(node ast.Node, s scope.Scope) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) {
+					for i := range n.Lhs {
+						if a, ok := assignedAppend(n.Lhs[i], n.Rhs[i], s); ok {
+							result = append(result, a)
+						}
+					}
+				}
+
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i := range n.Names {
+						if a, ok := assignedAppend(n.Names[i], n.Values[i], s); ok {
+							result = append(result, a)
+						}
+					}
+				}
+
+			case *ast.CallExpr:
+				source, ok := appendSource(n, s)
+				if !ok {
+					break
+				}
+
+				if param := parameterOf(source, s); param != nil {
+					result = append(result, AppendAlias{call: n, source: source, parameter: param})
+				}
+			}
+
+			return true
+		}, packageScope)
+	}
+
+	return result
+}
+
+func AppendAliasFindings(aliases []AppendAlias) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, a := range aliases {
+		findings = append(findings, a)
+	}
+
+	return findings
+}
+
+// assignedAppend matches `target = append(source, ...)` where target isn't
+// source itself. Appends to parameters are reported separately.
+func assignedAppend(target, value ast.Expr, s scope.Scope) (AppendAlias, bool) {
+	call, ok := ast.Unparen(value).(*ast.CallExpr)
+	if !ok || funkyAST.BlankIdentifier(funkyAST.IdentFromExpr(target)) {
+		return AppendAlias{}, false
+	}
+
+	source, ok := appendSource(call, s)
+	if !ok || parameterOf(source, s) != nil {
+		return AppendAlias{}, false
+	}
+
+	// Reslicing the target itself, as in `s = append(s[:i], s[i+1:]...)`, is
+	// an intentional in-place update.
+	whole := source
+	if slice, ok := source.(*ast.SliceExpr); ok {
+		whole = ast.Unparen(slice.X)
+	}
+
+	if types.ExprString(whole) == types.ExprString(target) {
+		return AppendAlias{}, false
+	}
+
+	return AppendAlias{call: call, source: source, target: target}, true
+}
+
+// appendSource returns the slice that a call to the append builtin appends
+// to, if it's a variable (or a two-index slice of one) whose backing array may
+// be shared. Full slice expressions like `a[:n:n]` cap the array, so appending
+// to them always allocates.
+func appendSource(call *ast.CallExpr, s scope.Scope) (ast.Expr, bool) {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || ident.Name != "append" || len(call.Args) < 2 {
+		return nil, false
+	}
+
+	// A local declaration named append shadows the builtin.
+	if _, shadowed := scope.Lookup(s, ident.Name); shadowed {
+		return nil, false
+	}
+
+	source := ast.Unparen(call.Args[0])
+
+	variable := source
+	if slice, ok := source.(*ast.SliceExpr); ok {
+		if slice.Slice3 {
+			return nil, false
+		}
+
+		variable = ast.Unparen(slice.X)
+	}
+
+	switch v := variable.(type) {
+	case *ast.Ident:
+		return source, v.Name != "nil"
+	case *ast.SelectorExpr:
+		return source, !isFromImportedPackage(v, s)
+	}
+
+	return nil, false
+}
+
+// parameterOf returns the parameter that expr is, or is a slice of.
+func parameterOf(expr ast.Expr, s scope.Scope) *ast.Ident {
+	if slice, ok := expr.(*ast.SliceExpr); ok {
+		expr = ast.Unparen(slice.X)
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	decl, ok := scope.Lookup(s, ident.Name)
+	if !ok || decl.Kind != scope.Parameter {
+		return nil
+	}
+
+	return ident
+}
+
+func fullSliceExpr(source ast.Expr, fset *token.FileSet) string {
+	if slice, ok := source.(*ast.SliceExpr); ok {
+		x := funkyAST.Render(slice.X, fset)
+
+		high := fmt.Sprintf("len(%s)", x)
+		if slice.High != nil {
+			high = funkyAST.Render(slice.High, fset)
+		}
+
+		return fmt.Sprintf("%s[%s:%s:%s]", x, renderOrEmpty(slice.Low, fset), high, high)
+	}
+
+	s := funkyAST.Render(source, fset)
+	return fmt.Sprintf("%s[:len(%s):len(%s)]", s, s, s)
+}
+
+func renderOrEmpty(expr ast.Expr, fset *token.FileSet) string {
+	if expr == nil {
+		return ""
+	}
+
+	return funkyAST.Render(expr, fset)
+}
@@ -0,0 +1,322 @@
+package mutation
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// receiverIndex identifies a method's receiver among its parameters.
+const receiverIndex = -1
+
+// WriteFacts records which of the functions and methods declared in a set of
+// packages write through their receiver or parameters, so that the values the
+// caller passes in are changed.
+type WriteFacts struct {
+	bodies map[*types.Func]funcBody
+	known  map[writeFact]bool
+}
+
+type writeFact struct {
+	fn    *types.Func
+	param int
+}
+
+// funcBody is a function's declaration, along with the type information of
+// the package it's declared in.
+type funcBody struct {
+	decl *ast.FuncDecl
+	info *types.Info
+}
+
+// NewWriteFacts records the functions declared in the packages, so that calls
+// from one package into another can be followed.
+func NewWriteFacts(pkgs []*packages.Package) *WriteFacts {
+	f := newWriteFacts()
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo != nil {
+			f.addFiles(pkg.Syntax, pkg.TypesInfo)
+		}
+	}
+
+	return f
+}
+
+// writeFactsOf returns facts, or when it's nil, the facts for the functions
+// declared in the files.
+func writeFactsOf(facts *WriteFacts, files []*ast.File, info *types.Info) *WriteFacts {
+	if facts != nil {
+		return facts
+	}
+
+	f := newWriteFacts()
+	f.addFiles(files, info)
+
+	return f
+}
+
+func newWriteFacts() *WriteFacts {
+	return &WriteFacts{
+		bodies: make(map[*types.Func]funcBody),
+		known:  make(map[writeFact]bool),
+	}
+}
+
+func (f *WriteFacts) addFiles(files []*ast.File, info *types.Info) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+
+			if fn, ok := info.Defs[funcDecl.Name].(*types.Func); ok {
+				f.bodies[fn] = funcBody{decl: funcDecl, info: info}
+			}
+		}
+	}
+}
+
+// writesThroughReceiver reports whether method modifies its receiver.
+// Methods whose bodies aren't available are assumed to.
+func (f *WriteFacts) writesThroughReceiver(method *types.Func) bool {
+	return f.writesThrough(writeFact{fn: method.Origin(), param: receiverIndex})
+}
+
+// writesThroughParameter reports whether fn writes through its parameter at
+// index i. Functions whose bodies aren't available are assumed to.
+func (f *WriteFacts) writesThroughParameter(fn *types.Func, i int) bool {
+	return f.writesThrough(writeFact{fn: fn.Origin(), param: i})
+}
+
+func (f *WriteFacts) writesThrough(fact writeFact) bool {
+	body, ok := f.bodies[fact.fn]
+	if !ok {
+		return true
+	}
+
+	if result, ok := f.known[fact]; ok {
+		return result
+	}
+
+	target := paramObject(body, fact)
+	if target == nil {
+		return false
+	}
+
+	// Assume nothing is written while the body is being checked, so that
+	// recursive functions terminate.
+	f.known[fact] = false
+
+	result := f.bodyWritesThrough(body, target)
+	f.known[fact] = result
+
+	return result
+}
+
+// paramObject returns the variable declared for the receiver or parameter, or
+// nil when it's unnamed (and so can't be written through).
+func paramObject(body funcBody, fact writeFact) types.Object {
+	decl := body.decl
+
+	if fact.param == receiverIndex {
+		if decl.Recv == nil || len(decl.Recv.List[0].Names) == 0 {
+			return nil
+		}
+
+		return body.info.Defs[decl.Recv.List[0].Names[0]]
+	}
+
+	params := fact.fn.Signature().Params()
+	if params.Len() == 0 {
+		return nil
+	}
+
+	// Arguments beyond the last parameter are part of a variadic parameter.
+	i := min(fact.param, params.Len()-1)
+
+	v := params.At(i)
+	if v.Name() == "" || v.Name() == "_" {
+		return nil
+	}
+
+	return v
+}
+
+// bodyWritesThrough reports whether body writes to part of the value that
+// target refers to, either directly, through an in-place function, or by
+// passing target on to a method or function that writes through it. Storing,
+// returning, sending or copying target lets the value be written elsewhere
+// later, so those count as writes too.
+func (f *WriteFacts) bodyWritesThrough(body funcBody, target types.Object) bool {
+	info := body.info
+
+	// partOfTarget matches expressions that refer to part of the target,
+	// rather than the target variable itself, which is a local copy.
+	partOfTarget := func(expr ast.Expr) bool {
+		_, isIdent := ast.Unparen(expr).(*ast.Ident)
+		return !isIdent && info.ObjectOf(funkyAST.RootIdentFromExpr(expr)) == target
+	}
+
+	isTarget := func(expr ast.Expr) bool {
+		ident, ok := ast.Unparen(expr).(*ast.Ident)
+		return ok && info.ObjectOf(ident) == target
+	}
+
+	holdsTarget := func(expr ast.Expr) bool {
+		return holds(expr, target, info)
+	}
+
+	found := false
+
+	ast.Inspect(body.decl.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					found = found || partOfTarget(lhs)
+				}
+			}
+
+			// e.g. `registry = append(registry, p)` or `q := p`
+			found = found || slices.ContainsFunc(n.Rhs, holdsTarget)
+
+		case *ast.ValueSpec:
+			found = found || slices.ContainsFunc(n.Values, holdsTarget)
+
+		case *ast.ReturnStmt:
+			found = found || slices.ContainsFunc(n.Results, holdsTarget)
+
+		case *ast.SendStmt:
+			found = found || holdsTarget(n.Value)
+
+		case *ast.IncDecStmt:
+			found = found || partOfTarget(n.X)
+
+		case *ast.CallExpr:
+			if arg, ok := inPlaceArgument(n, info); ok && info.ObjectOf(funkyAST.RootIdentFromExpr(arg)) == target {
+				found = true
+				break
+			}
+
+			if selector, method, ok := pointerMethodCall(n, info); ok && info.ObjectOf(funkyAST.RootIdentFromExpr(selector.X)) == target {
+				found = f.writesThroughReceiver(method)
+				if found {
+					break
+				}
+			}
+
+			for i, arg := range n.Args {
+				if !isTarget(arg) {
+					// e.g. `register(&entry{p})`, which can't be followed
+					found = holdsTarget(arg) && !isAppend(n, info)
+					if found {
+						break
+					}
+
+					continue
+				}
+
+				fn, ok := typeutil.Callee(info, n).(*types.Func)
+				if !ok {
+					// Calls through function values can't be followed.
+					_, isBuiltin := typeutil.Callee(info, n).(*types.Builtin)
+					found = !isBuiltin && !info.Types[n.Fun].IsType()
+				} else {
+					found = f.writesThroughParameter(fn, i)
+				}
+
+				if found {
+					break
+				}
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// holds reports whether expr evaluates to target itself or to a value that
+// holds on to it, such as a composite literal, an append or a slice of
+// target. Function literals that use target are inspected along with the rest
+// of the body, so anything they do with it is found there.
+func holds(expr ast.Expr, target types.Object, info *types.Info) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return info.ObjectOf(e) == target
+
+	case *ast.UnaryExpr:
+		return e.Op == token.AND && holds(e.X, target, info)
+
+	case *ast.SliceExpr:
+		return holds(e.X, target, info)
+
+	case *ast.KeyValueExpr:
+		return holds(e.Value, target, info)
+
+	case *ast.CompositeLit:
+		return slices.ContainsFunc(e.Elts, func(elt ast.Expr) bool { return holds(elt, target, info) })
+
+	case *ast.CallExpr:
+		// Conversions and appends hold on to their operands; other calls are
+		// followed on their own.
+		if !info.Types[e.Fun].IsType() && !isAppend(e, info) {
+			return false
+		}
+
+		return slices.ContainsFunc(e.Args, func(arg ast.Expr) bool { return holds(arg, target, info) })
+	}
+
+	return false
+}
+
+func isAppend(call *ast.CallExpr, info *types.Info) bool {
+	builtin, ok := typeutil.Callee(info, call).(*types.Builtin)
+	return ok && builtin.Name() == "append"
+}
+
+// inPlaceArgument returns the argument that a call to one of the in-place
+// functions modifies.
+func inPlaceArgument(call *ast.CallExpr, info *types.Info) (ast.Expr, bool) {
+	var pkg, name string
+
+	switch obj := info.Uses[calleeIdent(call)].(type) {
+	case *types.Builtin:
+		name = obj.Name()
+	case *types.Func:
+		if obj.Pkg() == nil {
+			return nil, false
+		}
+
+		pkg, name = obj.Pkg().Path(), obj.Name()
+	default:
+		return nil, false
+	}
+
+	for _, f := range InPlaceFuncs {
+		if f.Package == pkg && f.Name == name && !f.ViaCallback && f.Argument < len(call.Args) {
+			return call.Args[f.Argument], true
+		}
+	}
+
+	return nil, false
+}
+
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	}
+
+	return nil
+}
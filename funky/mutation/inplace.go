@@ -0,0 +1,159 @@
+package mutation
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/scope"
+)
+
+// InPlaceFunc is a function that modifies one of its arguments in place, rather
+// than returning a new value.
+type InPlaceFunc struct {
+	// Package is the import path of the function's package, or empty for builtins.
+	Package string
+	Name    string
+
+	// Argument is the position of the argument that's modified, starting at 0.
+	Argument int
+
+	// ViaCallback is true when the modification is made by a function passed as
+	// the argument (e.g. the swap function of rand.Shuffle), so the modified
+	// variable is the one the function writes to.
+	ViaCallback bool
+}
+
+// InPlaceFuncs lists the builtins and standard library functions that modify
+// their arguments in place.
+var InPlaceFuncs = []InPlaceFunc{
+	{Name: "delete", Argument: 0},
+	{Name: "clear", Argument: 0},
+	{Name: "copy", Argument: 0},
+	{Package: "sort", Name: "Ints", Argument: 0},
+	{Package: "sort", Name: "Float64s", Argument: 0},
+	{Package: "sort", Name: "Strings", Argument: 0},
+	{Package: "sort", Name: "Sort", Argument: 0},
+	{Package: "sort", Name: "Stable", Argument: 0},
+	{Package: "sort", Name: "Slice", Argument: 0},
+	{Package: "sort", Name: "SliceStable", Argument: 0},
+	{Package: "slices", Name: "Sort", Argument: 0},
+	{Package: "slices", Name: "SortFunc", Argument: 0},
+	{Package: "slices", Name: "SortStableFunc", Argument: 0},
+	{Package: "slices", Name: "Reverse", Argument: 0},
+	{Package: "slices", Name: "Delete", Argument: 0},
+	{Package: "slices", Name: "DeleteFunc", Argument: 0},
+	{Package: "slices", Name: "Compact", Argument: 0},
+	{Package: "slices", Name: "CompactFunc", Argument: 0},
+	{Package: "slices", Name: "Replace", Argument: 0},
+	{Package: "maps", Name: "Copy", Argument: 0},
+	{Package: "maps", Name: "DeleteFunc", Argument: 0},
+	{Package: "math/rand", Name: "Shuffle", Argument: 1, ViaCallback: true},
+	{Package: "math/rand/v2", Name: "Shuffle", Argument: 1, ViaCallback: true},
+}
+
+// inPlaceMutation returns the mutation made by a call to one of the in-place
+// functions, attributed to the variable at the root of the modified argument.
+func inPlaceMutation(call *ast.CallExpr, s scope.Scope) (Mutation, bool) {
+	f, ok := inPlaceFuncOf(call, s)
+	if !ok || f.Argument >= len(call.Args) {
+		return Mutation{}, false
+	}
+
+	arg := ast.Unparen(call.Args[f.Argument])
+
+	var write *ast.AssignStmt
+	if f.ViaCallback {
+		if write = callbackWrite(arg); write == nil {
+			return Mutation{}, false
+		}
+
+		arg = write.Lhs[0]
+	}
+
+	ident := funkyAST.RootIdentFromExpr(arg)
+	if ident == nil || funkyAST.BlankIdentifier(ident) || ident.Name == "nil" {
+		return Mutation{}, false
+	}
+
+	if decl, ok := scope.Lookup(s, ident.Name); ok && decl.Kind == scope.Function {
+		return Mutation{}, false
+	}
+
+	return Mutation{
+		node:           call,
+		mutatedVarExpr: ident,
+		inPlaceCall:    call,
+		callbackWrite:  write,
+	}, true
+}
+
+func inPlaceFuncOf(call *ast.CallExpr, s scope.Scope) (InPlaceFunc, bool) {
+	var pkg, name string
+
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		// A local declaration with the same name shadows the builtin.
+		if _, shadowed := scope.Lookup(s, fun.Name); shadowed {
+			return InPlaceFunc{}, false
+		}
+
+		name = fun.Name
+
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return InPlaceFunc{}, false
+		}
+
+		if _, shadowed := scope.Lookup(s, x.Name); shadowed {
+			return InPlaceFunc{}, false
+		}
+
+		spec := scope.LookupImport(s, x.Name)
+		if spec == nil {
+			return InPlaceFunc{}, false
+		}
+
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return InPlaceFunc{}, false
+		}
+
+		pkg, name = path, fun.Sel.Name
+
+	default:
+		return InPlaceFunc{}, false
+	}
+
+	for _, f := range InPlaceFuncs {
+		if f.Package == pkg && f.Name == name {
+			return f, true
+		}
+	}
+
+	return InPlaceFunc{}, false
+}
+
+// callbackWrite returns the first assignment to an existing variable made by a
+// function literal, e.g. `s[i], s[j] = s[j], s[i]` in
+// `func(i, j int) { s[i], s[j] = s[j], s[i] }`.
+func callbackWrite(expr ast.Expr) *ast.AssignStmt {
+	lit, ok := expr.(*ast.FuncLit)
+	if !ok {
+		return nil
+	}
+
+	var write *ast.AssignStmt
+
+	ast.Inspect(lit.Body, func(node ast.Node) bool {
+		if stmt, ok := node.(*ast.AssignStmt); ok && stmt.Tok != token.DEFINE && write == nil {
+			write = stmt
+		}
+
+		return write == nil
+	})
+
+	return write
+}
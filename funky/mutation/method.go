@@ -0,0 +1,232 @@
+package mutation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+)
+
+// MethodMutationType is the type of calls to pointer-receiver methods that
+// modify the variable they're called on.
+var MethodMutationType finding.Type = "method-mutation"
+
+// Enforce that MethodMutation implements the Finding type
+var _ finding.Finding = (*MethodMutation)(nil)
+
+// AllowedTypes lists the types whose whole purpose is to be modified through
+// their methods, so calling those methods isn't reported.
+var AllowedTypes = []string{
+	"strings.Builder",
+	"bytes.Buffer",
+	"sync.WaitGroup",
+	"sync.Mutex",
+	"sync.RWMutex",
+	"sync.Once",
+}
+
+// MethodMutation describes a call to a method with a pointer receiver on a
+// variable that isn't itself a pointer, like `buf.WriteString("x")`. Go takes
+// the variable's address implicitly, so the method can modify it.
+type MethodMutation struct {
+	call *ast.CallExpr
+
+	// receiver is the expression the method is called on, and variable is the
+	// variable at its root, e.g. `s.buf` and `s`.
+	receiver ast.Expr
+	variable *ast.Ident
+
+	method *types.Func
+
+	// pkg is the package the call is made in, used to qualify type names.
+	pkg *types.Package
+}
+
+func (m MethodMutation) Message(fset *token.FileSet) string {
+	receiver := funkyAST.Render(m.receiver, fset)
+	method := m.methodName()
+
+	if receiver == m.variable.Name {
+		return fmt.Sprintf("%q is modified by calling %s, which has a pointer receiver", m.variable.Name, method)
+	}
+
+	return fmt.Sprintf("%q is modified by calling %s on %s, which has a pointer receiver", m.variable.Name, method, receiver)
+}
+
+func (m MethodMutation) Type() finding.Type {
+	return MethodMutationType
+}
+
+func (m MethodMutation) Node() ast.Node {
+	return m.call
+}
+
+func (m MethodMutation) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(m.call.Pos()).String())
+}
+
+func (m MethodMutation) String() string {
+	return fmt.Sprintf("%q mutated by %s", m.variable.Name, m.method.Name())
+}
+
+func (m MethodMutation) methodName() string {
+	recv := types.TypeString(m.method.Signature().Recv().Type(), func(p *types.Package) string {
+		if p == m.pkg {
+			return ""
+		}
+
+		return p.Name()
+	})
+
+	return fmt.Sprintf("(%s).%s", recv, m.method.Name())
+}
+
+// FindMethodMutationsInFiles reports calls to pointer-receiver methods made on
+// variables that aren't pointers. Methods declared in the files, or in the
+// packages facts were recorded for, are only reported when they actually modify
+// their receiver, directly or through other methods; methods declared elsewhere
+// are assumed to. facts may be nil. Calls on values of the built-in and
+// configured allowed types are skipped.
+func FindMethodMutationsInFiles(files []*ast.File, info *types.Info, c config.Config, facts *WriteFacts) []MethodMutation {
+	if info == nil {
+		return nil
+	}
+
+	allowed := make(map[string]struct{})
+	for _, name := range append(append([]string(nil), AllowedTypes...), c.MethodMutations.AllowedTypes...) {
+		allowed[name] = struct{}{}
+	}
+
+	facts = writeFactsOf(facts, files, info)
+
+	var result []MethodMutation
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			selector, method, ok := pointerMethodCall(call, info)
+			if !ok || isPointer(info.TypeOf(selector.X)) {
+				return true
+			}
+
+			variable := funkyAST.RootIdentFromExpr(selector.X)
+			if v, ok := info.ObjectOf(variable).(*types.Var); !ok || v.IsField() {
+				return true
+			}
+
+			if _, ok := allowed[receiverTypeName(method)]; ok || !facts.writesThroughReceiver(method) {
+				return true
+			}
+
+			result = append(result, MethodMutation{
+				call:     call,
+				receiver: selector.X,
+				variable: variable,
+				method:   method,
+				pkg:      packageOf(info, variable),
+			})
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func MethodMutationFindings(mutations []MethodMutation) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, m := range mutations {
+		findings = append(findings, m)
+	}
+
+	return findings
+}
+
+// pointerMethodCall matches a call to a method with a pointer receiver. Calls
+// to methods promoted through an embedded pointer are excluded, since they
+// modify the value being pointed to rather than the variable.
+func pointerMethodCall(call *ast.CallExpr, info *types.Info) (*ast.SelectorExpr, *types.Func, bool) {
+	selector, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil, false
+	}
+
+	selection := info.Selections[selector]
+	if selection == nil || selection.Kind() != types.MethodVal || promotedThroughPointer(selection) {
+		return nil, nil, false
+	}
+
+	method, ok := selection.Obj().(*types.Func)
+	if !ok || method.Signature().Recv() == nil || !isPointer(method.Signature().Recv().Type()) {
+		return nil, nil, false
+	}
+
+	return selector, method.Origin(), true
+}
+
+// promotedThroughPointer reports whether a method is promoted from a field
+// that's embedded by pointer.
+func promotedThroughPointer(selection *types.Selection) bool {
+	t := selection.Recv()
+	indexes := selection.Index()
+
+	for _, i := range indexes[:len(indexes)-1] {
+		if pointer, ok := t.Underlying().(*types.Pointer); ok {
+			t = pointer.Elem()
+		}
+
+		s, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return false
+		}
+
+		t = s.Field(i).Type()
+		if isPointer(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// receiverTypeName returns the full name of the type a method is declared on,
+// e.g. "strings.Builder".
+func receiverTypeName(method *types.Func) string {
+	t := method.Signature().Recv().Type()
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+func isPointer(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
+
+func packageOf(info *types.Info, ident *ast.Ident) *types.Package {
+	if obj := info.ObjectOf(ident); obj != nil {
+		return obj.Pkg()
+	}
+
+	return nil
+}
@@ -4,25 +4,71 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"strings"
 
 	"github.com/luhring/funky/funky/assignment"
 	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
 	"github.com/luhring/funky/funky/finding"
 	"github.com/luhring/funky/funky/scope"
+	"golang.org/x/tools/go/analysis"
 )
 
-var Type finding.Type = "mutation"
+var (
+	Type finding.Type = "mutation"
 
-// Enforce that Mutation implements the Finding type
-var _ finding.Finding = (*Mutation)(nil)
+	// ErrReuseType is the type of mutations that reassign an `err` variable.
+	ErrReuseType finding.Type = "err-reuse"
+
+	// NamedResultType is the type of mutations that write to a named result parameter.
+	NamedResultType finding.Type = "named-result"
+
+	// ParamReassignmentType is the type of mutations that assign a new value to
+	// an incoming parameter. (Mutating a value through a parameter, such as
+	// `p.x = 1`, is an ordinary mutation.)
+	ParamReassignmentType finding.Type = "param-reassignment"
+)
+
+// Types are the types a Mutation can be reported as.
+var Types = []finding.Type{Type, ErrReuseType, NamedResultType, ParamReassignmentType}
+
+// Enforce that Mutation implements the Finding and Fixer types
+var (
+	_ finding.Finding = (*Mutation)(nil)
+	_ finding.Fixer   = (*Mutation)(nil)
+)
 
 type Mutation struct {
 	node           ast.Node
 	mutatedVarExpr ast.Expr
 	newValueExpr   ast.Expr
+	findingType    finding.Type
+
+	// errCheck is the `if err != nil` statement that the reassigned err
+	// variable could be scoped to, if any.
+	errCheck *ast.IfStmt
+
+	// deferred is true when the mutation happens inside a deferred function literal.
+	deferred bool
+
+	// declaration is the mutated variable's declaration, when it was found in scope.
+	declaration *scope.Declaration
+
+	// inPlaceCall is set when the mutation is made by a call to a function that
+	// modifies its argument in place, like `sort.Ints(s)` or `delete(m, k)`.
+	inPlaceCall *ast.CallExpr
+
+	// callbackWrite is the assignment that makes the modification, when it's
+	// made by a function passed to the in-place call, like the swap function
+	// of rand.Shuffle. It's reported as part of the call rather than on its own.
+	callbackWrite *ast.AssignStmt
 }
 
 func (m Mutation) Message(fset *token.FileSet) string {
+	if m.inPlaceCall != nil {
+		return fmt.Sprintf("%q was modified in place by %s", funkyAST.Render(m.mutatedVarExpr, fset), funkyAST.Render(m.inPlaceCall.Fun, fset))
+	}
+
 	var newValue string
 
 	if m.newValueExpr != nil {
@@ -31,11 +77,33 @@ func (m Mutation) Message(fset *token.FileSet) string {
 		newValue = "[unable to render expression]" // e.g. in the case of range stmt initializers
 	}
 
-	return fmt.Sprintf("%q was assigned a new value: %s", funkyAST.Render(m.mutatedVarExpr, fset), newValue)
+	message := fmt.Sprintf("%q was assigned a new value: %s", funkyAST.Render(m.mutatedVarExpr, fset), newValue)
+
+	if m.Type() == ParamReassignmentType {
+		return fmt.Sprintf("parameter %q (position %d) was reassigned: %s; introduce a new variable for the new value instead", varName(m), m.declaration.Index+1, newValue)
+	}
+
+	if m.Type() == NamedResultType {
+		message = "named result " + message
+
+		if m.deferred {
+			message += " (in a deferred function)"
+		}
+	}
+
+	if m.errCheck != nil {
+		message += fmt.Sprintf("; it isn't read after the error check that follows, so scope it to that check: if %s; %s", scopedErrInit(m, fset), funkyAST.Render(m.errCheck.Cond, fset))
+	}
+
+	return message
 }
 
 func (m Mutation) Type() finding.Type {
-	return Type
+	if m.findingType == "" {
+		return Type
+	}
+
+	return m.findingType
 }
 
 func (m Mutation) Node() ast.Node {
@@ -46,29 +114,125 @@ func (m Mutation) Location(fset *token.FileSet) finding.Location {
 	return finding.Location(fset.Position(m.node.Pos()).String())
 }
 
+// InDeferredFunc reports whether the mutation happens inside a deferred function literal.
+func (m Mutation) InDeferredFunc() bool {
+	return m.deferred
+}
+
 func (m Mutation) String() string {
 	return fmt.Sprintf("%q mutated", varName(m))
 }
 
+// SuggestedFixes moves a reassignment of err into the init statement of the
+// error check that follows it, when the reassigned value isn't read elsewhere.
+func (m Mutation) SuggestedFixes(fset *token.FileSet) []analysis.SuggestedFix {
+	if m.errCheck == nil {
+		return nil
+	}
+
+	return []analysis.SuggestedFix{
+		{
+			Message: "Scope err to the error check",
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos: m.node.Pos(),
+					End: m.errCheck.Pos(),
+				},
+				{
+					Pos:     m.errCheck.Cond.Pos(),
+					End:     m.errCheck.Cond.Pos(),
+					NewText: []byte(scopedErrInit(m, fset) + "; "),
+				},
+			},
+		},
+	}
+}
+
+func scopedErrInit(m Mutation, fset *token.FileSet) string {
+	stmt := m.node.(*ast.AssignStmt)
+
+	var lhs []string
+	for _, expr := range stmt.Lhs {
+		lhs = append(lhs, funkyAST.Render(expr, fset))
+	}
+
+	var rhs []string
+	for _, expr := range stmt.Rhs {
+		rhs = append(rhs, funkyAST.Render(expr, fset))
+	}
+
+	return fmt.Sprintf("%s := %s", strings.Join(lhs, ", "), strings.Join(rhs, ", "))
+}
+
 func FindInFiles(files []*ast.File) []Mutation {
 	var mutations []Mutation
 
 	packageScope := scope.FromFiles(files)
 
+	// callbackWrites holds the assignments already reported as part of the
+	// in-place calls they're passed to, which are visited first.
+	callbackWrites := make(map[*ast.AssignStmt]struct{})
+
+	// forPosts holds the post statements of the for loops seen so far, which
+	// are visited after their loops. Like `i++`, an assignment such as
+	// `i += 2` there only advances the loop and isn't reported.
+	forPosts := make(map[ast.Stmt]struct{})
+
 	for _, file := range files {
+		errChecks := errChecksInFile(file)
+		errCaptures := errCapturesInFile(file)
+		deferredFuncs := deferredFuncLitsInFile(file)
+
 		funkyAST.InspectWithInitialScope(file, func(node ast.Node, s scope.Scope) bool {
 			if node == nil {
 				return false
 			}
 
 			switch stmt := node.(type) {
+			case *ast.ForStmt:
+				if stmt.Post != nil {
+					forPosts[stmt.Post] = struct{}{}
+				}
+
 			case *ast.AssignStmt:
+				if _, ok := callbackWrites[stmt]; ok {
+					break
+				}
+
+				if _, ok := forPosts[stmt]; ok {
+					break
+				}
+
 				assignments := assignment.AssignmentsFromStmt(stmt)
-				mutations = append(mutations, mutationsFromAssignments(assignments, stmt, s)...)
+
+				for _, m := range mutationsFromAssignments(assignments, stmt, s) {
+					if m.Type() == ErrReuseType {
+						if _, ok := errCaptures[stmt]; ok {
+							continue
+						}
+
+						if errCheck, ok := errChecks[stmt]; ok && inCurrentScope(m, s) {
+							m.errCheck = errCheck
+						}
+					}
+
+					m.deferred = isWithinAny(stmt, deferredFuncs)
+					mutations = append(mutations, m)
+				}
 
 			case *ast.RangeStmt:
 				assignments := assignment.AssignmentsFromRangeStmtInitializer(stmt)
 				mutations = append(mutations, mutationsFromAssignments(assignments, stmt, scope.NewInsideExisting(s))...)
+
+			case *ast.CallExpr:
+				if m, ok := inPlaceMutation(stmt, s); ok {
+					m.deferred = isWithinAny(stmt, deferredFuncs)
+					mutations = append(mutations, m)
+
+					if m.callbackWrite != nil {
+						callbackWrites[m.callbackWrite] = struct{}{}
+					}
+				}
 			}
 
 			return true
@@ -87,7 +251,9 @@ func mutationsFromAssignments(assignments []assignment.Assignment, n ast.Node, s
 				node:           n,
 				mutatedVarExpr: a.VarExpr,
 				newValueExpr:   a.NewValueExpr,
+				declaration:    declarationOf(a, s),
 			}
+			mutation.findingType = findingType(a, mutation.declaration)
 			mutations = append(mutations, mutation)
 		}
 	}
@@ -115,6 +281,480 @@ func isFromImportedPackage(expr ast.Expr, s scope.Scope) bool {
 	return scope.HasImport(s, ident.Name)
 }
 
+func declarationOf(a assignment.Assignment, s scope.Scope) *scope.Declaration {
+	if a.Ident == nil {
+		return nil
+	}
+
+	decl, ok := scope.Lookup(s, a.Ident.Name)
+	if !ok {
+		return nil
+	}
+
+	return &decl
+}
+
+// findingType classifies a mutation by the kind of variable it writes to.
+func findingType(a assignment.Assignment, decl *scope.Declaration) finding.Type {
+	if a.Ident == nil {
+		return Type
+	}
+
+	if decl != nil {
+		switch decl.Kind {
+		case scope.Parameter:
+			return ParamReassignmentType
+		case scope.Result:
+			return NamedResultType
+		}
+	}
+
+	if a.Ident.Name == "err" {
+		return ErrReuseType
+	}
+
+	return Type
+}
+
+// Allowed reports whether the configuration exempts m from being reported.
+func Allowed(m Mutation, c config.Config) bool {
+	return m.Type() == NamedResultType && m.deferred && c.NamedResults.AllowInDefer
+}
+
+// WithoutAllowed drops the mutations that the configuration exempts from being reported.
+func WithoutAllowed(mutations []Mutation, c config.Config) []Mutation {
+	var result []Mutation
+
+	for _, m := range mutations {
+		if !Allowed(m, c) {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// deferredFuncLitsInFile finds the function literals that are deferred, e.g.
+// `defer func() { ... }()`.
+func deferredFuncLitsInFile(file *ast.File) []*ast.FuncLit {
+	var result []*ast.FuncLit
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if deferStmt, ok := node.(*ast.DeferStmt); ok {
+			if funcLit, ok := deferStmt.Call.Fun.(*ast.FuncLit); ok {
+				result = append(result, funcLit)
+			}
+		}
+
+		return true
+	})
+
+	return result
+}
+
+func isWithinAny(node ast.Node, funcLits []*ast.FuncLit) bool {
+	for _, funcLit := range funcLits {
+		if funcLit.Pos() <= node.Pos() && node.End() <= funcLit.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inCurrentScope(m Mutation, s scope.Scope) bool {
+	ident, ok := m.mutatedVarExpr.(*ast.Ident)
+	return ok && scope.InCurrent(s, ident)
+}
+
+// errCapturesInFile finds the assignments to err that come after a function
+// literal in the same function that reads err, such as a deferred closure that
+// reports the function's error. Reusing err there is how the closure sees the
+// new value, so the assignments aren't reported.
+func errCapturesInFile(file *ast.File) map[*ast.AssignStmt]struct{} {
+	result := make(map[*ast.AssignStmt]struct{})
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				errCapturesInFunc(n.Body, result)
+			}
+
+		case *ast.FuncLit:
+			errCapturesInFunc(n.Body, result)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// errCapturesInFunc adds the captured assignments to err in the function's
+// body to result, leaving out the bodies of function literals, which
+// errCapturesInFile visits on their own.
+func errCapturesInFunc(body *ast.BlockStmt, result map[*ast.AssignStmt]struct{}) {
+	captured := false
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			if capturesErr(n) {
+				captured = true
+			}
+
+			return false
+
+		case *ast.AssignStmt:
+			if captured && namesErr(n.Lhs...) {
+				result[n] = struct{}{}
+			}
+		}
+
+		return true
+	})
+}
+
+// capturesErr reports whether the function literal uses err before declaring
+// an err of its own, meaning the err it uses belongs to the enclosing function.
+func capturesErr(funcLit *ast.FuncLit) bool {
+	for _, fields := range []*ast.FieldList{funcLit.Type.Params, funcLit.Type.Results} {
+		if fields == nil {
+			continue
+		}
+
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if name.Name == "err" {
+					return false
+				}
+			}
+		}
+	}
+
+	declared := false
+	found := false
+
+	ast.Inspect(funcLit.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE && namesErr(n.Lhs...) {
+				declared = true
+			}
+
+		case *ast.DeclStmt:
+			if errSpec(n) != nil {
+				declared = true
+			}
+
+		case *ast.Ident:
+			if n.Name == "err" && !declared {
+				found = true
+			}
+		}
+
+		return !declared && !found
+	})
+
+	return found
+}
+
+// errChecksInFile finds reassignments of err whose value is only used by the
+// `if err != nil` statement that immediately follows them, and maps each of
+// them to that statement.
+func errChecksInFile(file *ast.File) map[*ast.AssignStmt]*ast.IfStmt {
+	result := make(map[*ast.AssignStmt]*ast.IfStmt)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				errChecksInFunc(n.Type, n.Body, result)
+			}
+
+		case *ast.FuncLit:
+			errChecksInFunc(n.Type, n.Body, result)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// errChecksInFunc adds the error checks in the function's body to result,
+// leaving out the bodies of function literals, which errChecksInFile visits
+// on their own.
+func errChecksInFunc(funcType *ast.FuncType, body *ast.BlockStmt, result map[*ast.AssignStmt]*ast.IfStmt) {
+	namedResults := hasNamedResults(funcType)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BlockStmt:
+			errChecksInList(n.List, namedResults, result)
+		case *ast.CaseClause:
+			errChecksInList(n.Body, namedResults, result)
+		case *ast.CommClause:
+			errChecksInList(n.Body, namedResults, result)
+		}
+
+		return true
+	})
+}
+
+func errChecksInList(list []ast.Stmt, namedResults bool, result map[*ast.AssignStmt]*ast.IfStmt) {
+	// checks holds the indexes of the reassignments that can be scoped,
+	// grouped by the index of the statement declaring err, or -1 when err is
+	// declared outside of the list.
+	checks := make(map[int][]int)
+
+	for i := 0; i+1 < len(list); i++ {
+		stmt, ok := list[i].(*ast.AssignStmt)
+		if !ok || !isErrAssignment(stmt) {
+			continue
+		}
+
+		ifStmt, ok := list[i+1].(*ast.IfStmt)
+		if !ok || ifStmt.Init != nil || !isErrCheck(ifStmt.Cond) {
+			continue
+		}
+
+		if readsErr(list[i+2:], namedResults) || !othersOnlyReadBy(ifStmt, stmt, list[i+2:], namedResults) {
+			continue
+		}
+
+		d := errDeclaration(list[:i])
+		checks[d] = append(checks[d], i)
+	}
+
+	for d, indexes := range checks {
+		// Scoping every reassignment of a variable that's declared without a
+		// value can leave it unused, which doesn't compile.
+		if d >= 0 && isDeclarationWithoutValue(list[d]) && !readsErrOutside(list, d+1, indexes) {
+			continue
+		}
+
+		for _, i := range indexes {
+			result[list[i].(*ast.AssignStmt)] = list[i+1].(*ast.IfStmt)
+		}
+	}
+}
+
+// errDeclaration returns the index of the last statement in the list that
+// declares err, or -1 if none of them do.
+func errDeclaration(list []ast.Stmt) int {
+	for i := len(list) - 1; i >= 0; i-- {
+		switch stmt := list[i].(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok == token.DEFINE && namesErr(stmt.Lhs...) {
+				return i
+			}
+
+		case *ast.DeclStmt:
+			if errSpec(stmt) != nil {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// isDeclarationWithoutValue matches `var err error`.
+func isDeclarationWithoutValue(stmt ast.Stmt) bool {
+	declStmt, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return false
+	}
+
+	spec := errSpec(declStmt)
+
+	return spec != nil && len(spec.Values) == 0
+}
+
+// errSpec returns the spec of a `var` declaration that declares err.
+func errSpec(stmt *ast.DeclStmt) *ast.ValueSpec {
+	genDecl, ok := stmt.Decl.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.VAR {
+		return nil
+	}
+
+	for _, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+
+		for _, name := range valueSpec.Names {
+			if name.Name == "err" {
+				return valueSpec
+			}
+		}
+	}
+
+	return nil
+}
+
+// readsErrOutside reports whether any of the statements from index start on
+// reads err, leaving out the reassignments at the given indexes and the error
+// checks that follow them.
+func readsErrOutside(list []ast.Stmt, start int, indexes []int) bool {
+	skipped := make(map[int]struct{})
+	for _, i := range indexes {
+		skipped[i] = struct{}{}
+		skipped[i+1] = struct{}{}
+	}
+
+	for i := start; i < len(list); i++ {
+		if _, ok := skipped[i]; ok {
+			continue
+		}
+
+		if readsErrIn(list[i], false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func namesErr(exprs ...ast.Expr) bool {
+	for _, expr := range exprs {
+		if ident := funkyAST.IdentFromExpr(expr); ident != nil && ident.Name == "err" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasNamedResults(funcType *ast.FuncType) bool {
+	return funcType.Results != nil && len(funcType.Results.List) > 0 && len(funcType.Results.List[0].Names) > 0
+}
+
+// isErrAssignment matches assignments of err to plain variables, like
+// `err = f()`, `_, err = f()` and `x, err := f()`.
+func isErrAssignment(stmt *ast.AssignStmt) bool {
+	if stmt.Tok != token.ASSIGN && stmt.Tok != token.DEFINE {
+		return false
+	}
+
+	errCount := 0
+
+	for _, expr := range stmt.Lhs {
+		ident := funkyAST.IdentFromExpr(expr)
+
+		switch {
+		case ident == nil:
+			return false
+		case ident.Name == "err":
+			errCount++
+		}
+	}
+
+	return errCount == 1
+}
+
+// othersOnlyReadBy reports whether the variables that stmt assigns along with
+// err, like x in `x, err := f()`, are read by the error check but by none of
+// the statements after it. Scoping them to the check would otherwise leave them
+// unused or take them away from the statements that read them.
+func othersOnlyReadBy(ifStmt *ast.IfStmt, stmt *ast.AssignStmt, after []ast.Stmt, namedResults bool) bool {
+	for _, expr := range stmt.Lhs {
+		ident := funkyAST.IdentFromExpr(expr)
+		if ident.Name == "err" || funkyAST.BlankIdentifier(ident) {
+			continue
+		}
+
+		if !readsVarIn(ifStmt, ident.Name, false) {
+			return false
+		}
+
+		for _, s := range after {
+			if readsVarIn(s, ident.Name, namedResults) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isErrCheck matches `err != nil` and `nil != err`.
+func isErrCheck(expr ast.Expr) bool {
+	binary, ok := expr.(*ast.BinaryExpr)
+	if !ok || binary.Op != token.NEQ {
+		return false
+	}
+
+	isIdent := func(expr ast.Expr, name string) bool {
+		ident := funkyAST.IdentFromExpr(expr)
+		return ident != nil && ident.Name == name
+	}
+
+	return (isIdent(binary.X, "err") && isIdent(binary.Y, "nil")) ||
+		(isIdent(binary.X, "nil") && isIdent(binary.Y, "err"))
+}
+
+// readsErr reports whether any of the statements could read the current value
+// of err, stopping at the first statement that overwrites it. Being the target
+// of an assignment doesn't count as a read, but a bare return does when the
+// function has named results, since it returns their current values.
+func readsErr(stmts []ast.Stmt, namedResults bool) bool {
+	for _, stmt := range stmts {
+		if readsErrIn(stmt, namedResults) {
+			return true
+		}
+
+		if assignStmt, ok := stmt.(*ast.AssignStmt); ok && isErrAssignment(assignStmt) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// readsErrIn reports whether the statement reads err, counting bare returns
+// as reads when bareReturns is true.
+func readsErrIn(stmt ast.Stmt, bareReturns bool) bool {
+	return readsVarIn(stmt, "err", bareReturns)
+}
+
+// readsVarIn reports whether the statement reads the named variable, counting
+// bare returns as reads when bareReturns is true.
+func readsVarIn(stmt ast.Stmt, name string, bareReturns bool) bool {
+	written := make(map[*ast.Ident]struct{})
+	found := false
+
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for _, expr := range n.Lhs {
+				if ident := funkyAST.IdentFromExpr(expr); ident != nil {
+					written[ident] = struct{}{}
+				}
+			}
+
+		case *ast.ReturnStmt:
+			if len(n.Results) == 0 && bareReturns {
+				found = true
+			}
+
+		case *ast.Ident:
+			if _, ok := written[n]; !ok && n.Name == name {
+				found = true
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
 // varName returns the name of the variable being mutated
 func varName(m Mutation) string {
 	if ident := funkyAST.IdentFromExpr(m.mutatedVarExpr); ident != nil {
@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/config"
 	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
 )
 
 func TestFindInFiles(t *testing.T) {
@@ -173,6 +175,11 @@ func TestFindInFiles(t *testing.T) {
 			variableName:     "some.name",
 			newValueRendered: "\"barney\"",
 		},
+		{
+			location:         "testdata/mixed/main.go:238:3",
+			variableName:     "total",
+			newValueRendered: "1",
+		},
 	}
 
 	fset := token.NewFileSet()
@@ -271,3 +278,452 @@ func mapToTestableMutation(mutation Mutation, fset *token.FileSet) testableMutat
 		newValueRendered: funkyAST.Render(mutation.newValueExpr, fset),
 	}
 }
+
+func TestFindInFiles_errReuse(t *testing.T) {
+	type testableErrReuse struct {
+		location finding.Location
+		scopable bool
+	}
+
+	expected := []testableErrReuse{
+		{location: "testdata/errreuse/main.go:11:2", scopable: true},
+		{location: "testdata/errreuse/main.go:16:2", scopable: true},
+		{location: "testdata/errreuse/main.go:30:2", scopable: false},
+		{location: "testdata/errreuse/main.go:44:2", scopable: false},
+		{location: "testdata/errreuse/main.go:56:3", scopable: false},
+		{location: "testdata/errreuse/main.go:68:2", scopable: false},
+		{location: "testdata/errreuse/main.go:80:2", scopable: true},
+		{location: "testdata/errreuse/main.go:94:2", scopable: false},
+		{location: "testdata/errreuse/main.go:108:2", scopable: true},
+		{location: "testdata/errreuse/main.go:122:2", scopable: false},
+		{location: "testdata/errreuse/main.go:138:2", scopable: true},
+		{location: "testdata/errreuse/main.go:170:2", scopable: true},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "errreuse")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	var actual []testableErrReuse
+
+	for _, m := range FindInFiles(files) {
+		if m.Type() != ErrReuseType {
+			continue
+		}
+
+		actual = append(actual, testableErrReuse{
+			location: m.Location(fset),
+			scopable: len(m.SuggestedFixes(fset)) > 0,
+		})
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d err-reuse findings, but found %d: %+v", len(expected), len(actual), actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual[i])
+		}
+	}
+}
+
+func TestFindInFiles_namedResults(t *testing.T) {
+	type testableNamedResult struct {
+		location finding.Location
+		deferred bool
+	}
+
+	expected := []testableNamedResult{
+		{location: "testdata/namedresults/main.go:11:4", deferred: true},
+		{location: "testdata/namedresults/main.go:21:4", deferred: true},
+		{location: "testdata/namedresults/main.go:25:2", deferred: false},
+		{location: "testdata/namedresults/main.go:31:3", deferred: false},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "namedresults")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	mutations := FindInFiles(files)
+
+	var actual []testableNamedResult
+
+	for _, m := range mutations {
+		if m.Type() != NamedResultType {
+			t.Errorf("expected only named result mutations, but found %s at %s", m.Type(), m.Location(fset))
+			continue
+		}
+
+		actual = append(actual, testableNamedResult{
+			location: m.Location(fset),
+			deferred: m.InDeferredFunc(),
+		})
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d named result mutations, but found %d: %+v", len(expected), len(actual), actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual[i])
+		}
+	}
+
+	c := config.Default()
+	c.NamedResults.AllowInDefer = true
+
+	if remaining := WithoutAllowed(mutations, c); len(remaining) != 2 {
+		t.Errorf("expected 2 mutations outside of deferred functions, but found %d", len(remaining))
+	}
+}
+
+func TestFindInFiles_paramReassignment(t *testing.T) {
+	type testableMutationType struct {
+		location    finding.Location
+		findingType finding.Type
+		message     string
+	}
+
+	expected := []testableMutationType{
+		{
+			location:    "testdata/params/main.go:10:2",
+			findingType: ParamReassignmentType,
+			message:     `parameter "path" (position 1) was reassigned: filepath.Clean(path); introduce a new variable for the new value instead`,
+		},
+		{
+			location:    "testdata/params/main.go:15:2",
+			findingType: ParamReassignmentType,
+			message:     `parameter "b" (position 2) was reassigned: a * 2; introduce a new variable for the new value instead`,
+		},
+		{
+			location:    "testdata/params/main.go:20:2",
+			findingType: Type,
+			message:     `"o.verbose" was assigned a new value: true`,
+		},
+		{
+			location:    "testdata/params/main.go:25:3",
+			findingType: ParamReassignmentType,
+			message:     `parameter "n" (position 1) was reassigned: 0; introduce a new variable for the new value instead`,
+		},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "params")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	mutations := FindInFiles(files)
+
+	if len(mutations) != len(expected) {
+		t.Fatalf("expected %d mutations, but found %d", len(expected), len(mutations))
+	}
+
+	for i, m := range mutations {
+		actual := testableMutationType{
+			location:    m.Location(fset),
+			findingType: m.Type(),
+			message:     m.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindAppendAliasesInFiles(t *testing.T) {
+	type testableAppendAlias struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableAppendAlias{
+		{"testdata/appends/main.go:8:9", `append to parameter "base" may write into the caller's backing array when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: base[:len(base):len(base)]`},
+		{"testdata/appends/main.go:16:10", `append to parameter "items" may write into the caller's backing array when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: items[1:len(items):len(items)]`},
+		{"testdata/appends/main.go:24:7", `"b" is assigned append(a, "y"), which may write into the backing array of "a" when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: a[:len(a):len(a)]`},
+		{"testdata/appends/main.go:25:10", `"c" is assigned append(a[:1], "z"), which may write into the backing array of "a[:1]" when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: a[:1:1]`},
+		{"testdata/appends/main.go:33:7", `"f" is assigned append(l.items, "u"), which may write into the backing array of "l.items" when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: l.items[:len(l.items):len(l.items)]`},
+		{"testdata/appends/main.go:49:10", `"b" is assigned append(a[:1], 3), which may write into the backing array of "a[:1]" when it has spare capacity; copy it first (e.g. slices.Clone) or limit its capacity with a full slice expression: a[:1:1]`},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "appends")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	aliases := FindAppendAliasesInFiles(files)
+
+	if len(aliases) != len(expected) {
+		for _, a := range aliases {
+			t.Log(a.Location(fset), a.Message(fset))
+		}
+
+		t.Fatalf("expected %d append aliases, but found %d", len(expected), len(aliases))
+	}
+
+	for i, a := range aliases {
+		actual := testableAppendAlias{
+			location: a.Location(fset),
+			message:  a.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindInFiles_inPlace(t *testing.T) {
+	type testableInPlaceMutation struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableInPlaceMutation{
+		{"testdata/inplace/main.go:15:2", `"names" was modified in place by sort.Strings`},
+		{"testdata/inplace/main.go:16:2", `"names" was modified in place by slices.Reverse`},
+		{"testdata/inplace/main.go:19:2", `"inv" was modified in place by delete`},
+		{"testdata/inplace/main.go:22:7", `"buf" was modified in place by copy`},
+		{"testdata/inplace/main.go:24:2", `"buf" was modified in place by rand.Shuffle`},
+		{"testdata/inplace/main.go:28:2", `"inv" was modified in place by clear`},
+		{"testdata/inplace/main.go:41:10", `"dst" was modified in place by copy`},
+		{"testdata/inplace/main.go:46:30", `"names" was modified in place by sort.Strings`},
+		{"testdata/inplace/main.go:50:6", `"dst" was modified in place by copy`},
+		{"testdata/inplace/main.go:54:9", `"names" was modified in place by slices.Compact`},
+		{"testdata/inplace/main.go:58:13", `"names" was modified in place by slices.Compact`},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "inplace")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	mutations := FindInFiles(files)
+
+	if len(mutations) != len(expected) {
+		for _, m := range mutations {
+			t.Log(m.Location(fset), m.Message(fset))
+		}
+
+		t.Fatalf("expected %d mutations, but found %d", len(expected), len(mutations))
+	}
+
+	for i, m := range mutations {
+		actual := testableInPlaceMutation{
+			location: m.Location(fset),
+			message:  m.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindMethodMutationsInFiles(t *testing.T) {
+	type testableMethodMutation struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableMethodMutation{
+		{"testdata/methods/main.go:41:2", `"c" is modified by calling (*counter).increment, which has a pointer receiver`},
+		{"testdata/methods/main.go:42:2", `"c" is modified by calling (*counter).forget, which has a pointer receiver`},
+		{"testdata/methods/main.go:43:2", `"c" is modified by calling (*counter).reset, which has a pointer receiver`},
+		{"testdata/methods/main.go:56:2", `"r" is modified by calling (*counter).increment on r.total, which has a pointer receiver`},
+		{"testdata/methods/main.go:59:2", `"global" is modified by calling (*counter).increment, which has a pointer receiver`},
+		{"testdata/methods/main.go:62:6", `"t" is modified by calling (*time.Time).UnmarshalText, which has a pointer receiver`},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "methods/main.go")
+
+	mutations := FindMethodMutationsInFiles([]*ast.File{file}, info, config.Default(), nil)
+
+	if len(mutations) != len(expected) {
+		for _, m := range mutations {
+			t.Log(m.Location(fset), m.Message(fset))
+		}
+
+		t.Fatalf("expected %d method mutations, but found %d", len(expected), len(mutations))
+	}
+
+	for i, m := range mutations {
+		actual := testableMethodMutation{
+			location: m.Location(fset),
+			message:  m.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+
+	c := config.Default()
+	c.MethodMutations.AllowedTypes = []string{"main.counter"}
+
+	if remaining := FindMethodMutationsInFiles([]*ast.File{file}, info, c, nil); len(remaining) != 1 {
+		t.Errorf("expected 1 method mutation once counter is allowed, but found %d", len(remaining))
+	}
+}
+
+func TestFindInFiles_siblingPackages(t *testing.T) {
+	expected := []string{
+		`testdata/siblings/app/main.go:12:2: "p" is modified by calling (*shapes.Point).Shift, which has a pointer receiver`,
+		`testdata/siblings/app/main.go:15:14: "p" may be modified through its address, which is passed to example.com/siblings/shapes.Move`,
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "siblings")
+	app := pkgs[0]
+	facts := NewWriteFacts(pkgs)
+
+	var actual []string
+
+	for _, m := range FindMethodMutationsInFiles(app.Syntax, app.TypesInfo, config.Default(), facts) {
+		actual = append(actual, fmt.Sprintf("%s: %s", fixture.RelativeLocation(t, m.Location(fset)), m.Message(fset)))
+	}
+
+	for _, a := range FindAddressesTakenInFiles(app.Syntax, app.TypesInfo, facts) {
+		actual = append(actual, fmt.Sprintf("%s: %s", fixture.RelativeLocation(t, a.Location(fset)), a.Message(fset)))
+	}
+
+	if len(actual) != len(expected) {
+		for _, a := range actual {
+			t.Log(a)
+		}
+
+		t.Fatalf("expected %d findings, but found %d", len(expected), len(actual))
+	}
+
+	for i := range actual {
+		if actual[i] != expected[i] {
+			t.Errorf("expected %q, but found %q", expected[i], actual[i])
+		}
+	}
+}
+
+func TestFindAddressesTakenInFiles(t *testing.T) {
+	type testableAddressTaken struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableAddressTaken{
+		{"testdata/addresses/main.go:28:7", `"o" may be modified through its address, which is passed to main.fill`},
+		{"testdata/addresses/main.go:30:10", `"o" may be modified through its address, which is passed to main.forward`},
+		{"testdata/addresses/main.go:33:17", `"name" may be modified through its address, which is passed to flag.StringVar`},
+		{"testdata/addresses/main.go:36:35", `"data" may be modified through its address, which is passed to encoding/json.Unmarshal`},
+		{"testdata/addresses/main.go:38:7", `"o" may be modified through its address, which is stored in p`},
+		{"testdata/addresses/main.go:39:27", `"name" may be modified through its address, which is stored in field name`},
+		{"testdata/addresses/main.go:42:8", `"o" may be modified through its address, which is passed to apply`},
+		{"testdata/addresses/main.go:74:11", `"o" may be modified through its address, which is passed to main.register`},
+		{"testdata/addresses/main.go:75:15", `"o" may be modified through its address, which is passed to main.identity`},
+		{"testdata/addresses/main.go:76:19", `"o" may be modified through its address, which is passed to main.writeThroughCopy`},
+		{"testdata/addresses/main.go:77:7", `"o" may be modified through its address, which is passed to main.send`},
+		{"testdata/addresses/main.go:78:7", `"o" may be modified through its address, which is passed to main.wrap`},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "addresses/main.go")
+
+	addresses := FindAddressesTakenInFiles([]*ast.File{file}, info, nil)
+
+	if len(addresses) != len(expected) {
+		for _, a := range addresses {
+			t.Log(a.Location(fset), a.Message(fset))
+		}
+
+		t.Fatalf("expected %d addresses taken, but found %d", len(expected), len(addresses))
+	}
+
+	for i, a := range addresses {
+		actual := testableAddressTaken{
+			location: a.Location(fset),
+			message:  a.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestFindInFiles_selectCases(t *testing.T) {
+	expected := newTestableMutationSet([]testableMutation{
+		{
+			location:         "testdata/selects/main.go:9:3",
+			variableName:     "total",
+			newValueRendered: "v",
+		},
+		{
+			location:         "testdata/selects/main.go:12:3",
+			variableName:     "total",
+			newValueRendered: "v",
+		},
+	})
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "selects")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	actual := newTestableMutationSet(mapToTestableMutations(FindInFiles(files), fset))
+
+	assertEqualTestableMutationSets(t, expected, actual)
+}
+
+func TestFindInFiles_generics(t *testing.T) {
+	type testableMutationType struct {
+		location    finding.Location
+		findingType finding.Type
+		message     string
+	}
+
+	expected := []testableMutationType{
+		{"testdata/generics/main.go:14:2", Type, `"s.items" was assigned a new value: append(s.items, v)`},
+		{"testdata/generics/main.go:23:2", Type, `"p.key" was assigned a new value: k`},
+		{"testdata/generics/main.go:24:2", Type, `"p.value" was assigned a new value: v`},
+		{"testdata/generics/main.go:29:3", ParamReassignmentType, `parameter "v" (position 1) was reassigned: lo; introduce a new variable for the new value instead`},
+		{"testdata/generics/main.go:38:3", Type, `"total" was assigned a new value: x`},
+		{"testdata/generics/main.go:47:3", Type, `"result" was assigned a new value: append(result, f(x))`},
+		{"testdata/generics/main.go:57:3", Type, `"calls" was assigned a new value: len(s)`},
+		{"testdata/generics/main.go:62:2", Type, `"counts[Sum[int](lengths)]" was assigned a new value: calls`},
+	}
+
+	fset := token.NewFileSet()
+	packages := loadGoSourceTestFixture(t, fset, "generics")
+	files := funkyAST.SortedFilesFromPackage(packages["main"])
+
+	mutations := FindInFiles(files)
+
+	if len(mutations) != len(expected) {
+		for _, m := range mutations {
+			t.Log(m.Location(fset), m.Type(), m.Message(fset))
+		}
+
+		t.Fatalf("expected %d mutations, but found %d", len(expected), len(mutations))
+	}
+
+	for i, m := range mutations {
+		actual := testableMutationType{
+			location:    m.Location(fset),
+			findingType: m.Type(),
+			message:     m.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+
+	file, info := fixture.TypedFile(t, fset, "generics/main.go")
+
+	methodMutations := FindMethodMutationsInFiles([]*ast.File{file}, info, config.Default(), nil)
+	if len(methodMutations) != 1 {
+		t.Fatalf("expected 1 method mutation, but found %d", len(methodMutations))
+	}
+
+	expectedMessage := `"stack" is modified by calling (*Stack[T]).Push, which has a pointer receiver`
+	if actual := methodMutations[0].Message(fset); actual != expectedMessage {
+		t.Errorf("expected %q, but found %q", expectedMessage, actual)
+	}
+}
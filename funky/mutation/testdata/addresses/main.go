@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+)
+
+type options struct {
+	name    *string
+	verbose bool
+}
+
+func fill(o *options) {
+	o.verbose = true
+}
+
+func describe(o *options) string {
+	return fmt.Sprint(o.verbose)
+}
+
+func forward(o *options) {
+	fill(o)
+}
+
+func main() {
+	var o options
+	fill(&o)
+	_ = describe(&o)
+	forward(&o)
+
+	var name string
+	flag.StringVar(&name, "name", "", "the name")
+
+	var data map[string]int
+	_ = json.Unmarshal([]byte("{}"), &data)
+
+	p := &o.verbose
+	wrapper := options{name: &name}
+
+	apply := func(o *options) {}
+	apply(&o)
+
+	_, _ = p, wrapper
+	_ = &options{}
+}
+
+var registry []*options
+
+func register(o *options) {
+	registry = append(registry, o)
+}
+
+func identity(o *options) *options {
+	return o
+}
+
+func writeThroughCopy(o *options) {
+	q := o
+	q.verbose = true
+}
+
+func send(o *options, ch chan *options) {
+	ch <- o
+}
+
+func wrap(o *options) {
+	register(&options{name: o.name})
+	_ = []*options{o}
+}
+
+func escapes() {
+	var o options
+	register(&o)         // stored in a package-level slice
+	_ = identity(&o)     // returned to the caller
+	writeThroughCopy(&o) // written through a copy of the pointer
+	send(&o, nil)        // sent on a channel
+	wrap(&o)             // stored in a composite literal
+	_ = describe(&o)     // only read
+}
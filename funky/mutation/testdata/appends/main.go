@@ -0,0 +1,52 @@
+package main
+
+type list struct {
+	items []string
+}
+
+func extend(base []string, extra string) []string {
+	return append(base, extra)
+}
+
+func extendCopy(base []string, extra string) []string {
+	return append(base[:len(base):len(base)], extra)
+}
+
+func rest(items []string) []string {
+	items = append(items[1:], "end")
+	return items
+}
+
+func main() {
+	a := make([]string, 0, 10)
+	a = append(a, "x")
+
+	b := append(a, "y")
+	var c = append(a[:1], "z")
+	d := append([]string(nil), a...)
+	e := append(a[:1:1], "w")
+
+	a = append(a[:0], a[1:]...)
+
+	l := list{}
+	l.items = append(l.items, "v")
+	f := append(l.items, "u")
+
+	_ = append(a, "ignored")
+
+	_, _, _, _, _ = b, c, d, e, f
+}
+
+func withShadowedAppend() {
+	append := func(s []int, v int) []int { return s }
+	x := []int{1}
+	y := append(x, 2)
+	_ = y
+}
+
+func inIfInit() {
+	a := []int{1, 2}
+	if b := append(a[:1], 3); len(b) > 0 { // in an if statement's init
+		return
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import "os"
+
+func scopable() error {
+	err := os.Chdir("/")
+	if err != nil {
+		return err
+	}
+
+	err = os.Chdir("/tmp") // err-reuse, can be scoped
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat("/tmp") // err-reuse, can be scoped
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func readLater() error {
+	err := os.Chdir("/")
+	if err != nil {
+		return err
+	}
+
+	err = os.Chdir("/tmp") // err-reuse, can't be scoped: read after the check
+	if err != nil {
+		print(err)
+	}
+
+	return err
+}
+
+func otherValueKept() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	wd, err = os.Getwd() // err-reuse and mutation, can't be scoped: wd is used later
+	if err != nil {
+		return "", err
+	}
+
+	return wd, nil
+}
+
+func declaredInOuterScope(ok bool) {
+	var err error
+
+	if ok {
+		err = os.Chdir("/") // err-reuse, can't be scoped: err belongs to the outer scope
+		if err != nil {
+			return
+		}
+	}
+
+	print(err)
+}
+
+func declaredWithoutValue() error {
+	var err error
+
+	err = os.Chdir("/") // err-reuse, can't be scoped: err wouldn't be used anymore
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func declaredWithoutValueAndRead() error {
+	var err error
+	print(err)
+
+	err = os.Chdir("/") // err-reuse, can be scoped: err is still read before
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func bareReturnWithNamedResults() (wd string, e error) {
+	err := os.Chdir("/")
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Chdir("/tmp") // err-reuse, can't be scoped: the bare return reads the results
+	if err != nil {
+		print(err)
+	}
+
+	return
+}
+
+func bareReturnWithoutResults() {
+	err := os.Chdir("/")
+	if err != nil {
+		return
+	}
+
+	err = os.Chdir("/tmp") // err-reuse, can be scoped
+	if err != nil {
+		print(err)
+	}
+
+	return
+}
+
+func declaresOtherValue() error {
+	err := os.Chdir("/")
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd() // err-reuse, can't be scoped: wd is used later
+	if err != nil {
+		return err
+	}
+
+	print(wd)
+
+	return nil
+}
+
+func declaresOtherValueOnlyChecked() error {
+	err := os.Chdir("/")
+	if err != nil {
+		return err
+	}
+
+	wd, err := os.Getwd() // err-reuse, can be scoped: wd is only read by the check
+	if err != nil {
+		print(wd)
+		return err
+	}
+
+	return nil
+}
+
+func readByDeferredClosure() {
+	err := os.Chdir("/")
+	defer func() {
+		if err != nil {
+			print(err)
+		}
+	}()
+
+	err = os.Chdir("/tmp") // not reported: the deferred closure reads the new value
+	if err != nil {
+		return
+	}
+}
+
+func closureDeclaresItsOwn() error {
+	err := os.Chdir("/")
+	check := func() {
+		if err := os.Chdir("/"); err != nil {
+			print(err)
+		}
+	}
+	check()
+
+	err = os.Chdir("/tmp") // err-reuse, can be scoped: the closure has its own err
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func main() {}
@@ -0,0 +1,66 @@
+package main
+
+import "cmp"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type Stack[T any] struct {
+	items []T
+}
+
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v) // mutation
+}
+
+type Pair[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func (p *Pair[K, V]) Set(k K, v V) {
+	p.key = k   // mutation
+	p.value = v // mutation
+}
+
+func Clamp[T cmp.Ordered](v, lo, hi T) T {
+	if v < lo {
+		v = lo // param reassignment
+	}
+
+	return min(v, hi)
+}
+
+func Sum[T Number](xs []T) T {
+	var total T
+	for _, x := range xs {
+		total += x // mutation
+	}
+
+	return total
+}
+
+func Map[T, U any](xs []T, f func(T) U) []U {
+	result := make([]U, 0, len(xs))
+	for _, x := range xs {
+		result = append(result, f(x)) // mutation
+	}
+
+	return result
+}
+
+func main() {
+	calls := 0
+
+	lengths := Map[string, int]([]string{"a", "bb"}, func(s string) int {
+		calls += len(s) // mutation
+		return len(s)
+	})
+
+	counts := map[int]int{}
+	counts[Sum[int](lengths)] = calls // mutation
+
+	var stack Stack[int]
+	stack.Push(len(counts)) // method mutation
+}
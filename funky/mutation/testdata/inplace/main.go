@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+)
+
+type inventory struct {
+	counts map[string]int
+}
+
+func main() {
+	names := []string{"b", "a"}
+	sort.Strings(names)
+	slices.Reverse(names)
+
+	inv := inventory{counts: map[string]int{"a": 1}}
+	delete(inv.counts, "a")
+
+	buf := make([]string, len(names))
+	n := copy(buf, names)
+
+	rand.Shuffle(len(buf), func(i, j int) {
+		buf[i], buf[j] = buf[j], buf[i] // reported as part of the call to rand.Shuffle
+	})
+
+	clear(inv.counts)
+
+	sorted := slices.Sorted(slices.Values(names))
+	_, _ = n, sorted
+}
+
+func withShadowedSort(sort []int) {
+	copy := func(dst, src []int) int { return 0 }
+	_ = copy(sort, nil)
+}
+
+func inConditions() {
+	dst := make([]int, 2)
+	if n := copy(dst, []int{1}); n > 0 { // in an if statement's init
+		return
+	}
+
+	names := []string{"b", "a"}
+	for i := 0; i < len(names); sort.Strings(names) { // in a for statement's post
+		i++
+	}
+
+	for copy(dst, dst[1:]) > 0 { // in a for statement's condition
+		break
+	}
+
+	if len(slices.Compact(names)) > 1 { // in an if statement's condition
+		return
+	}
+
+	switch len(slices.Compact(names)) { // in a switch statement's tag
+	case 0:
+		return
+	}
+}
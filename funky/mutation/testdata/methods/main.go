@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	n     int
+	names map[string]int
+}
+
+func (c *counter) increment() {
+	c.n++
+}
+
+func (c *counter) forget(name string) {
+	delete(c.names, name)
+}
+
+func (c *counter) reset() {
+	c.increment()
+	c = nil
+}
+
+func (c *counter) value() int {
+	return c.n
+}
+
+type report struct {
+	total counter
+	out   bytes.Buffer
+}
+
+var global counter
+
+func main() {
+	var c counter
+	c.increment()
+	c.forget("x")
+	c.reset()
+	_ = c.value()
+
+	var sb strings.Builder
+	sb.WriteString("x")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	p := &counter{}
+	p.increment()
+
+	var r report
+	r.total.increment()
+	r.out.WriteString("x")
+
+	global.increment()
+
+	var t time.Time
+	_ = t.UnmarshalText([]byte("2021-01-01T00:00:00Z"))
+}
@@ -231,6 +231,16 @@ func otherPackageMaskedByLocalVar() {
 	some.name = "barney" // mutation
 }
 
+func immediatelyInvoked() {
+	total := 0
+
+	func() {
+		total = 1 // mutation, in a function literal that's called right away
+	}()
+
+	print(total)
+}
+
 func main() {
 
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func wrapError() (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("wrapped: %w", err) // named result, deferred
+		}
+	}()
+
+	return errors.New("failed")
+}
+
+func recoverPanic() (result int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered: %v", r) // named result, deferred
+		}
+	}()
+
+	result = 42 // named result
+	return
+}
+
+func closureNotDeferred() (n int) {
+	f := func() {
+		n = 1 // named result, not deferred
+	}
+	f()
+	return
+}
+
+func main() {}
@@ -0,0 +1,29 @@
+package main
+
+import "path/filepath"
+
+type options struct {
+	verbose bool
+}
+
+func clean(path string) string {
+	path = filepath.Clean(path) // param reassignment
+	return path
+}
+
+func second(a, b int) int {
+	b = a * 2 // param reassignment
+	return b
+}
+
+func throughParameter(o *options) {
+	o.verbose = true // mutation, not a param reassignment
+}
+
+func inClosure(n int) func() {
+	return func() {
+		n = 0 // param reassignment
+	}
+}
+
+func main() {}
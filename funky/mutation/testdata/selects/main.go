@@ -0,0 +1,16 @@
+package main
+
+func main() {
+	ch := make(chan int, 1)
+	total := 0
+
+	select {
+	case v := <-ch:
+		total = v
+	case ch <- total:
+		v := 2
+		total = v
+	}
+
+	_ = total
+}
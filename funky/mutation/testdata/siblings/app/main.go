@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/siblings/shapes"
+)
+
+func main() {
+	var p shapes.Point
+	fmt.Println(p.String())
+	p.Shift(2)
+
+	fmt.Println(shapes.Describe(&p))
+	shapes.Move(&p)
+}
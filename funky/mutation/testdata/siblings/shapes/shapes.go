@@ -0,0 +1,23 @@
+package shapes
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+func (p *Point) Shift(dx int) {
+	p.X += dx
+}
+
+func Describe(p *Point) string {
+	return p.String()
+}
+
+func Move(p *Point) {
+	p.Shift(1)
+}
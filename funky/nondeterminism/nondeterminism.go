@@ -0,0 +1,379 @@
+package nondeterminism
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "nondeterminism"
+
+// Enforce that Nondeterminism implements the Finding type
+var _ finding.Finding = (*Nondeterminism)(nil)
+
+// Nondeterminism describes an expression whose result can differ between two
+// runs given the same inputs: a call to a function that depends on the clock,
+// randomness or the environment, or a loop that depends on map iteration order.
+type Nondeterminism struct {
+	node ast.Node
+
+	// callee is the name of the function being called, for calls.
+	callee string
+	kinds  effect.Set
+
+	// orderDependence explains how a map range loop depends on iteration order.
+	orderDependence string
+}
+
+func (n Nondeterminism) Message(fset *token.FileSet) string {
+	if loop, ok := n.node.(*ast.RangeStmt); ok {
+		return fmt.Sprintf("iteration order of map %s is unspecified, but the loop %s", funkyAST.Render(loop.X, fset), n.orderDependence)
+	}
+
+	return fmt.Sprintf("call to %s is nondeterministic (%s)", n.callee, n.kinds)
+}
+
+func (n Nondeterminism) Type() finding.Type {
+	return Type
+}
+
+func (n Nondeterminism) Node() ast.Node {
+	return n.node
+}
+
+func (n Nondeterminism) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(n.node.Pos()).String())
+}
+
+func (n Nondeterminism) String() string {
+	if n.callee != "" {
+		return fmt.Sprintf("nondeterministic call to %s", n.callee)
+	}
+
+	return "map iteration order dependence"
+}
+
+// Effects returns the kinds of nondeterminism involved.
+func (n Nondeterminism) Effects() effect.Set {
+	return n.kinds
+}
+
+func FindInFiles(files []*ast.File, info *types.Info, catalog effect.Catalog) []Nondeterminism {
+	if info == nil {
+		return nil
+	}
+
+	var result []Nondeterminism
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				if d, ok := nondeterministicCall(n, info, catalog); ok {
+					result = append(result, d)
+				}
+
+			case *ast.RangeStmt:
+				if d, ok := orderDependentMapRange(n, file, info); ok {
+					result = append(result, d)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(nondeterminisms []Nondeterminism) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, n := range nondeterminisms {
+		findings = append(findings, n)
+	}
+
+	return findings
+}
+
+func nondeterministicCall(call *ast.CallExpr, info *types.Info, catalog effect.Catalog) (Nondeterminism, bool) {
+	effects, callee := effect.OfCall(call, info, catalog)
+
+	kinds := effect.NewSet()
+	for _, k := range effect.Nondeterministic {
+		if effects.Has(k) {
+			kinds[k] = struct{}{}
+		}
+	}
+
+	if len(kinds) == 0 {
+		return Nondeterminism{}, false
+	}
+
+	return Nondeterminism{
+		node:   call,
+		callee: callee,
+		kinds:  kinds,
+	}, true
+}
+
+func orderDependentMapRange(loop *ast.RangeStmt, file *ast.File, info *types.Info) (Nondeterminism, bool) {
+	t := info.TypeOf(loop.X)
+	if t == nil {
+		return Nondeterminism{}, false
+	}
+
+	if _, ok := t.Underlying().(*types.Map); !ok {
+		return Nondeterminism{}, false
+	}
+
+	dependence := orderDependence(loop, file, info)
+	if dependence == "" {
+		return Nondeterminism{}, false
+	}
+
+	return Nondeterminism{
+		node:            loop,
+		kinds:           effect.NewSet(effect.Randomness),
+		orderDependence: dependence,
+	}, true
+}
+
+// orderDependence describes the first thing in the loop's body whose outcome
+// depends on the order of iteration, or returns an empty string if the body
+// looks order-independent (e.g. summing values or copying into another map).
+func orderDependence(loop *ast.RangeStmt, file *ast.File, info *types.Info) string {
+	var dependence string
+
+	ast.Inspect(loop.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+
+		case *ast.ReturnStmt:
+			// Returning something that doesn't depend on the element, like
+			// `return true` once a match is found, gives the same result
+			// whichever element is visited first.
+			for _, result := range n.Results {
+				if referencesLoopState(result, loop, info) {
+					dependence = "returns from inside the loop"
+				}
+			}
+
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident := funkyAST.IdentFromExpr(lhs)
+				if ident == nil || !declaredOutside(ident, loop, info) {
+					continue
+				}
+
+				switch {
+				case n.Tok == token.ADD_ASSIGN && isString(info.TypeOf(lhs)):
+					dependence = fmt.Sprintf("concatenates onto %q", ident.Name)
+
+				case n.Tok != token.ASSIGN || len(n.Lhs) != len(n.Rhs):
+					continue
+
+				case isAppendTo(n.Rhs[i], ident, info) && !sortedAfter(ident, loop, file, info):
+					// Collecting keys and sorting them afterwards is the usual way
+					// to iterate over a map in a fixed order.
+					dependence = fmt.Sprintf("appends to %q", ident.Name)
+
+				case !references(n.Rhs[i], ident, info) && referencesLoopVariable(n.Rhs[i], loop, info):
+					// e.g. `last = k`, where the final value is whichever element came last
+					dependence = fmt.Sprintf("overwrites %q with a different element on each iteration", ident.Name)
+				}
+			}
+		}
+
+		return dependence == ""
+	})
+
+	if dependence == "" && leavesEarly(loop.Body, false, loop, info) {
+		dependence = "leaves the loop early"
+	}
+
+	return dependence
+}
+
+// leavesEarly reports whether node contains a break or goto that leaves the
+// loop. An unlabeled break inside a switch, select or loop nested in the loop
+// only leaves that statement, so once nested, only labeled branches count.
+func leavesEarly(node ast.Node, nested bool, loop *ast.RangeStmt, info *types.Info) bool {
+	found := false
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.ForStmt, *ast.RangeStmt:
+			if !nested && n != node {
+				found = found || leavesEarly(n, true, loop, info)
+				return false
+			}
+
+		case *ast.BranchStmt:
+			switch {
+			case n.Tok != token.BREAK && n.Tok != token.GOTO:
+			case n.Label == nil:
+				found = found || !nested
+			default:
+				found = found || targetsOutside(n.Label, loop, info)
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// targetsOutside reports whether label names a statement that isn't inside
+// the loop's body, which is the loop itself or something enclosing it for a
+// break, so that branching to it leaves the loop.
+func targetsOutside(label *ast.Ident, loop *ast.RangeStmt, info *types.Info) bool {
+	obj := info.ObjectOf(label)
+	if obj == nil {
+		return false
+	}
+
+	return obj.Pos() < loop.Body.Pos() || obj.Pos() > loop.Body.End()
+}
+
+func declaredOutside(ident *ast.Ident, loop *ast.RangeStmt, info *types.Info) bool {
+	obj := info.ObjectOf(ident)
+	if obj == nil {
+		return false
+	}
+
+	return obj.Pos() < loop.Pos() || obj.Pos() > loop.End()
+}
+
+func isAppendTo(expr ast.Expr, ident *ast.Ident, info *types.Info) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+
+	fun := funkyAST.IdentFromExpr(call.Fun)
+	if fun == nil {
+		return false
+	}
+
+	if _, isBuiltin := info.ObjectOf(fun).(*types.Builtin); !isBuiltin || fun.Name != "append" || len(call.Args) == 0 {
+		return false
+	}
+
+	first := funkyAST.IdentFromExpr(call.Args[0])
+	return first != nil && info.ObjectOf(first) == info.ObjectOf(ident)
+}
+
+// sortedAfter reports whether the slice that ident refers to is sorted after
+// the loop, by one of the sort package's sorting functions or slices.Sort*.
+func sortedAfter(ident *ast.Ident, loop *ast.RangeStmt, file *ast.File, info *types.Info) bool {
+	target := info.ObjectOf(ident)
+	found := false
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Pos() < loop.End() || len(call.Args) == 0 || !isSortFunc(typeutil.Callee(info, call)) {
+			return !found
+		}
+
+		arg := ast.Unparen(call.Args[0])
+
+		// e.g. sort.Sort(byName(names))
+		if conversion, ok := arg.(*ast.CallExpr); ok && info.Types[conversion.Fun].IsType() && len(conversion.Args) == 1 {
+			arg = ast.Unparen(conversion.Args[0])
+		}
+
+		if argIdent, ok := arg.(*ast.Ident); ok && info.ObjectOf(argIdent) == target {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func isSortFunc(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Signature().Recv() != nil {
+		return false
+	}
+
+	switch fn.Pkg().Path() {
+	case "sort":
+		switch fn.Name() {
+		case "Sort", "Stable", "Slice", "SliceStable", "Strings", "Ints", "Float64s":
+			return true
+		}
+	case "slices":
+		return strings.HasPrefix(fn.Name(), "Sort")
+	}
+
+	return false
+}
+
+// references reports whether expr refers to the same variable as ident.
+func references(expr ast.Expr, ident *ast.Ident, info *types.Info) bool {
+	target := info.ObjectOf(ident)
+	found := false
+
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if i, ok := node.(*ast.Ident); ok && info.ObjectOf(i) == target {
+			found = true
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func referencesLoopVariable(expr ast.Expr, loop *ast.RangeStmt, info *types.Info) bool {
+	for _, v := range []ast.Expr{loop.Key, loop.Value} {
+		if ident := funkyAST.IdentFromExpr(v); ident != nil && !funkyAST.BlankIdentifier(ident) && references(expr, ident, info) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// referencesLoopState reports whether expr refers to a variable declared by
+// the loop, which includes its key and value and anything derived from them in
+// its body.
+func referencesLoopState(expr ast.Expr, loop *ast.RangeStmt, info *types.Info) bool {
+	found := false
+
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			if v, ok := info.ObjectOf(ident).(*types.Var); ok && !declaredOutside(ident, loop, info) && !v.IsField() {
+				found = true
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func isString(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
@@ -0,0 +1,58 @@
+package nondeterminism
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableNondeterminism struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableNondeterminism{
+		{"testdata/nondeterministic/main.go:13:9", "call to time.Now is nondeterministic (time)"},
+		{"testdata/nondeterministic/main.go:17:7", "call to math/rand.New is nondeterministic (randomness)"},
+		{"testdata/nondeterministic/main.go:17:16", "call to math/rand.NewSource is nondeterministic (randomness)"},
+		{"testdata/nondeterministic/main.go:18:22", "call to math/rand.Intn is nondeterministic (randomness)"},
+		{"testdata/nondeterministic/main.go:22:9", "call to crypto/rand.Read is nondeterministic (randomness)"},
+		{"testdata/nondeterministic/main.go:26:9", "call to os.Getenv is nondeterministic (environment)"},
+		{"testdata/nondeterministic/main.go:31:2", `iteration order of map m is unspecified, but the loop appends to "result"`},
+		{"testdata/nondeterministic/main.go:58:2", `iteration order of map m is unspecified, but the loop appends to "result"`},
+		{"testdata/nondeterministic/main.go:83:2", "iteration order of map m is unspecified, but the loop returns from inside the loop"},
+		{"testdata/nondeterministic/main.go:99:2", "iteration order of map m is unspecified, but the loop returns from inside the loop"},
+		{"testdata/nondeterministic/main.go:108:2", "iteration order of map m is unspecified, but the loop leaves the loop early"},
+		{"testdata/nondeterministic/main.go:158:2", "iteration order of map m is unspecified, but the loop leaves the loop early"},
+		{"testdata/nondeterministic/main.go:185:2", "iteration order of map m is unspecified, but the loop leaves the loop early"},
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "nondeterministic/main.go")
+
+	nondeterminisms := FindInFiles([]*ast.File{file}, info, effect.DefaultCatalog())
+
+	if len(nondeterminisms) != len(expected) {
+		for _, n := range nondeterminisms {
+			t.Log(n.Location(fset), n.Message(fset))
+		}
+
+		t.Fatalf("expected %d nondeterministic expressions, but found %d", len(expected), len(nondeterminisms))
+	}
+
+	for i, n := range nondeterminisms {
+		actual := testableNondeterminism{
+			location: n.Location(fset),
+			message:  n.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
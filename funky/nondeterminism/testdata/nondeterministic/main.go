@@ -0,0 +1,195 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+func clock() time.Time {
+	return time.Now() // nondeterministic
+}
+
+func random() int {
+	r := rand.New(rand.NewSource(1))  // nondeterministic (twice)
+	return r.Intn(10) + rand.Intn(10) // nondeterministic once: r has its own source
+}
+
+func cryptoRandom(b []byte) {
+	_, _ = crand.Read(b) // nondeterministic
+}
+
+func environment() string {
+	return os.Getenv("HOME") // nondeterministic
+}
+
+func keys(m map[string]int) []string {
+	var result []string
+	for k := range m { // nondeterministic: appends to result
+		result = append(result, k)
+	}
+	return result
+}
+
+func sortedKeys(m map[string]int) []string {
+	var result []string
+	for k := range m { // not nondeterministic: the keys are sorted afterwards
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedValues(m map[string]int) []int {
+	var result []int
+	for _, v := range m { // not nondeterministic: the values are sorted afterwards
+		result = append(result, v)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func sortedBeforeAppending(m map[string]int) []string {
+	var result []string
+	sort.Strings(result)
+	for k := range m { // nondeterministic: appends to result, which isn't sorted afterwards
+		result = append(result, k)
+	}
+	return result
+}
+
+func sum(m map[string]int) int {
+	total := 0
+	for _, v := range m { // not nondeterministic: addition is order-independent
+		total += v
+	}
+	return total
+}
+
+func contains(m map[string]int, target int) bool {
+	found := false
+	for _, v := range m { // not nondeterministic: the flag doesn't depend on which element set it
+		if v == target {
+			found = true
+		}
+	}
+	return found
+}
+
+func anyKey(m map[string]int) string {
+	for k := range m { // nondeterministic: returns from inside the loop
+		return k
+	}
+	return ""
+}
+
+func hasTarget(m map[string]int, target int) bool {
+	for _, v := range m { // not nondeterministic: the result doesn't depend on which element matched
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func anyDoubled(m map[string]int) int {
+	for _, v := range m { // nondeterministic: returns a value derived from the element
+		doubled := v * 2
+		return doubled
+	}
+	return 0
+}
+
+func countBeforeNegative(m map[string]int) int {
+	n := 0
+	for _, v := range m { // nondeterministic: leaves the loop early
+		if v < 0 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func breakInSwitch(m map[string]int) int {
+	total := 0
+	for _, v := range m { // not nondeterministic: the break only leaves the switch
+		switch {
+		case v > 0:
+			total += v
+			break
+		}
+	}
+	return total
+}
+
+func breakInSelect(m map[string]int, done chan struct{}) int {
+	total := 0
+	for _, v := range m { // not nondeterministic: the break only leaves the select
+		select {
+		case <-done:
+			break
+		default:
+			total += v
+		}
+	}
+	return total
+}
+
+func breakInInnerLoop(m map[string][]int) int {
+	total := 0
+	for _, vs := range m { // not nondeterministic: the break only leaves the inner loop
+		for _, v := range vs {
+			if v < 0 {
+				break
+			}
+			total += v
+		}
+	}
+	return total
+}
+
+func labeledBreak(m map[string]int) int {
+	n := 0
+loop:
+	for _, v := range m { // nondeterministic: leaves the loop early
+		switch {
+		case v < 0:
+			break loop
+		}
+		n++
+	}
+	return n
+}
+
+func labeledBreakOfInnerLoop(m map[string][]int) int {
+	total := 0
+	for _, vs := range m { // not nondeterministic: the break only leaves the inner loop
+	inner:
+		for _, v := range vs {
+			switch {
+			case v < 0:
+				break inner
+			}
+			total += v
+		}
+	}
+	return total
+}
+
+func gotoOutOfLoop(m map[string]int) int {
+	n := 0
+	for _, v := range m { // nondeterministic: leaves the loop early
+		if v < 0 {
+			goto done
+		}
+		n++
+	}
+done:
+	return n
+}
+
+func main() {}
@@ -0,0 +1,228 @@
+package packageinit
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/profile"
+	"golang.org/x/tools/go/packages"
+)
+
+// Step is a single part of initializing a program: the initialization of one
+// or more package-level variables from the same expression, or a call to an
+// init function.
+type Step struct {
+	Package string `json:"package"`
+
+	// Kind is "var" for variable initializers and "init" for init functions.
+	Kind string `json:"kind"`
+
+	// Name is the name of the variables being initialized, or "init".
+	Name string `json:"name"`
+
+	// Position is the file and line the step is declared at, e.g. "main.go:12".
+	Position string `json:"position"`
+
+	Effects []effect.Kind `json:"effects,omitempty"`
+
+	// Writes lists the package-level variables the step modifies.
+	Writes []string `json:"writes,omitempty"`
+}
+
+// Order returns the steps that initialize the packages, in the order Go
+// performs them: packages are initialized after the packages they import,
+// and within a package, variables are initialized in dependency order before
+// the init functions are called in the order their files are presented to the
+// compiler.
+func Order(pkgs []*packages.Package, c effect.Catalog, g *effect.Graph) []Step {
+	var steps []Step
+
+	for _, pkg := range dependencyOrder(pkgs) {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, initializer := range pkg.TypesInfo.InitOrder {
+			var vars []string
+			for _, v := range initializer.Lhs {
+				vars = append(vars, v.Name())
+			}
+
+			step := Step{
+				Package:  pkg.PkgPath,
+				Kind:     "var",
+				Name:     strings.Join(vars, ", "),
+				Position: position(pkg, initializer.Lhs[0].Pos()),
+			}
+
+			steps = append(steps, withEffects(step, findInNode(initializer.Rhs, vars, pkg.TypesInfo, c, g)))
+		}
+
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if funcDecl, ok := decl.(*ast.FuncDecl); ok && isInitFunc(funcDecl) {
+					step := Step{
+						Package:  pkg.PkgPath,
+						Kind:     "init",
+						Name:     "init",
+						Position: position(pkg, funcDecl.Pos()),
+					}
+
+					steps = append(steps, withEffects(step, findInNode(funcDecl.Body, nil, pkg.TypesInfo, c, g)))
+				}
+			}
+		}
+	}
+
+	return steps
+}
+
+// Describe summarizes what the step does beyond computing values, e.g.
+// "network, writes registry", or "-" when it does nothing else.
+func Describe(s Step) string {
+	var parts []string
+
+	for _, k := range s.Effects {
+		parts = append(parts, string(k))
+	}
+
+	for _, name := range s.Writes {
+		parts = append(parts, fmt.Sprintf("writes %s", name))
+	}
+
+	if len(parts) == 0 {
+		return "-"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Write writes the steps to w in the given format.
+func Write(w io.Writer, steps []Step, f profile.Format) error {
+	switch f {
+	case profile.Table:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PACKAGE\tKIND\tNAME\tPOSITION\tEFFECTS")
+
+		for _, s := range steps {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Package, s.Kind, s.Name, s.Position, Describe(s))
+		}
+
+		return tw.Flush()
+
+	case profile.Markdown:
+		fmt.Fprintln(w, "| Package | Kind | Name | Position | Effects |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+
+		for _, s := range steps {
+			fmt.Fprintf(w, "| `%s` | %s | `%s` | %s | %s |\n", s.Package, s.Kind, s.Name, s.Position, Describe(s))
+		}
+
+		return nil
+
+	case profile.JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		// An empty list should be written as [] rather than null.
+		if steps == nil {
+			steps = []Step{}
+		}
+
+		return encoder.Encode(steps)
+	}
+
+	return fmt.Errorf("unknown format %q (expected %q, %q or %q)", f, profile.Table, profile.Markdown, profile.JSON)
+}
+
+// withEffects adds the kinds of effect and the writes made by the step.
+func withEffects(s Step, effects []Effect) Step {
+	kinds := effect.NewSet()
+	writes := make(map[string]struct{})
+
+	for _, e := range effects {
+		if e.global != nil {
+			writes[types.ExprString(e.global)] = struct{}{}
+			continue
+		}
+
+		kinds = effect.Union(kinds, e.summary.Effects)
+	}
+
+	s.Effects = kinds.Kinds()
+
+	for name := range writes {
+		s.Writes = append(s.Writes, name)
+	}
+
+	sort.Strings(s.Writes)
+
+	return s
+}
+
+// dependencyOrder sorts the packages so that each one comes after the
+// packages it imports. Packages that don't depend on each other are ordered
+// by path.
+func dependencyOrder(pkgs []*packages.Package) []*packages.Package {
+	byPath := make(map[string]*packages.Package)
+	for _, pkg := range pkgs {
+		byPath[pkg.PkgPath] = pkg
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	var result []*packages.Package
+
+	visited := make(map[string]bool)
+
+	var visit func(path string)
+	visit = func(path string) {
+		pkg, ok := byPath[path]
+		if !ok || visited[path] {
+			return
+		}
+
+		visited[path] = true
+
+		var imports []string
+		if pkg.Types != nil {
+			for _, imported := range pkg.Types.Imports() {
+				imports = append(imports, imported.Path())
+			}
+		}
+
+		sort.Strings(imports)
+
+		for _, imported := range imports {
+			visit(imported)
+		}
+
+		result = append(result, pkg)
+	}
+
+	for _, path := range paths {
+		visit(path)
+	}
+
+	return result
+}
+
+// position returns the name of the file pos is in, along with its line.
+func position(pkg *packages.Package, pos token.Pos) string {
+	p := pkg.Fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(p.Filename), p.Line)
+}
@@ -0,0 +1,300 @@
+package packageinit
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/nondeterminism"
+	"github.com/luhring/funky/funky/termination"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Type finding.Type = "init-effect"
+
+// Enforce that Effect implements the Finding and Aggregate types
+var (
+	_ finding.Finding   = (*Effect)(nil)
+	_ finding.Aggregate = (*Effect)(nil)
+)
+
+// Effect describes something a package does while it's being initialized: a
+// call or variable use with effects, or a write to a package-level variable,
+// made in an init function or in the initializer of a package-level variable.
+// These happen as soon as the package is imported, whether or not the
+// importer wants them to.
+type Effect struct {
+	node ast.Node
+
+	// vars names the package-level variables whose initializer this is part
+	// of, and is empty for init functions.
+	vars []string
+
+	// name and summary describe the function or variable being used, for
+	// uses with effects.
+	name    string
+	summary effect.Summary
+
+	// global is the package-level variable being modified, for writes, e.g.
+	// `registry` or `http.DefaultClient`.
+	global ast.Expr
+}
+
+func (e Effect) Message(_ *token.FileSet) string {
+	if e.global != nil {
+		return fmt.Sprintf("%s modifies package variable %q", e.subject(), types.ExprString(e.global))
+	}
+
+	verb := "calls"
+	if _, isCall := e.node.(*ast.CallExpr); !isCall {
+		verb = "uses"
+	}
+
+	return fmt.Sprintf("%s %s %s, which has effects: %s", e.subject(), verb, e.name, e.summary)
+}
+
+// subject describes where the effect happens, e.g. "init function" or
+// "initializer of \"client\"".
+func (e Effect) subject() string {
+	if len(e.vars) == 0 {
+		return "init function"
+	}
+
+	return fmt.Sprintf("initializer of %q", strings.Join(e.vars, ", "))
+}
+
+func (e Effect) Type() finding.Type {
+	return Type
+}
+
+func (e Effect) Node() ast.Node {
+	return e.node
+}
+
+func (e Effect) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(e.node.Pos()).String())
+}
+
+func (e Effect) String() string {
+	if e.global != nil {
+		return fmt.Sprintf("%s writes %s", e.subject(), types.ExprString(e.global))
+	}
+
+	return fmt.Sprintf("%s uses %s (%s)", e.subject(), e.name, e.summary.Effects)
+}
+
+// Subsumes returns the node of the effect, which the effect and mutation rules
+// would otherwise report without saying that it happens during initialization.
+func (e Effect) Subsumes() []ast.Node {
+	return []ast.Node{e.node}
+}
+
+// SubsumedTypes returns the types of the effect, mutation, nondeterminism and
+// termination findings that the node would otherwise get.
+func (e Effect) SubsumedTypes() []finding.Type {
+	return append([]finding.Type{effect.Type, nondeterminism.Type, termination.Type}, mutation.Types...)
+}
+
+// FindInFiles reports the effects and package-level variable writes of the
+// files' init functions and package-level variable initializers. Function
+// literals are only followed when they're called right away, since otherwise
+// their bodies don't run during initialization. When g isn't nil, calls to
+// functions the catalog doesn't know about are reported with the effects of
+// the functions they call in turn.
+func FindInFiles(files []*ast.File, info *types.Info, c effect.Catalog, g *effect.Graph) []Effect {
+	if info == nil {
+		return nil
+	}
+
+	var result []Effect
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if isInitFunc(d) {
+					result = append(result, findInNode(d.Body, nil, info, c, g)...)
+				}
+
+			case *ast.GenDecl:
+				if d.Tok != token.VAR {
+					continue
+				}
+
+				for _, spec := range d.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+
+					for i, value := range valueSpec.Values {
+						result = append(result, findInNode(value, initialized(valueSpec, i), info, c, g)...)
+					}
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func Findings(effects []Effect) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, e := range effects {
+		findings = append(findings, e)
+	}
+
+	return findings
+}
+
+// findInNode reports the effects and writes within node, which is the body of
+// an init function or the initializer of vars.
+func findInNode(node ast.Node, vars []string, info *types.Info, c effect.Catalog, g *effect.Graph) []Effect {
+	var result []Effect
+
+	// invoked holds the function literals that are called where they're
+	// declared, whose bodies are followed instead of reporting the call.
+	invoked := make(map[*ast.FuncLit]struct{})
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			_, ok := invoked[n]
+			return ok
+
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				break
+			}
+
+			for _, lhs := range n.Lhs {
+				if global := packageVariable(lhs, info); global != nil {
+					result = append(result, Effect{node: n, vars: vars, global: global})
+					break
+				}
+			}
+
+		case *ast.IncDecStmt:
+			if global := packageVariable(n.X, info); global != nil {
+				result = append(result, Effect{node: n, vars: vars, global: global})
+			}
+
+		case *ast.CallExpr:
+			if lit, ok := ast.Unparen(n.Fun).(*ast.FuncLit); ok {
+				invoked[lit] = struct{}{}
+				break
+			}
+
+			if effects, name := effect.OfCall(n, info, c); len(effects) > 0 {
+				result = append(result, Effect{node: n, vars: vars, name: name, summary: effect.Summary{Effects: effects}})
+			} else if summary := withoutMustPanic(g.OfCallSite(n), n, info); len(summary.Effects) > 0 {
+				result = append(result, Effect{node: n, vars: vars, name: calleeName(n, info), summary: summary})
+			}
+
+		case *ast.Ident:
+			if v, ok := info.Uses[n].(*types.Var); ok && isPackageLevel(v) {
+				if effects := effect.Lookup(c, v); len(effects) > 0 {
+					result = append(result, Effect{node: n, vars: vars, name: effect.ObjectName(v), summary: effect.Summary{Effects: effects}})
+				}
+			}
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// withoutMustPanic drops the termination effect from the summary of a call to
+// a Must helper, like mustParse in `var config = mustParse(data)`. Panicking
+// on invalid input is what such helpers are for, and during initialization the
+// panic reports a programming error as soon as the program starts.
+func withoutMustPanic(summary effect.Summary, call *ast.CallExpr, info *types.Info) effect.Summary {
+	if !summary.Effects.Has(effect.Termination) || !effect.IsMust(typeutil.Callee(info, call)) {
+		return summary
+	}
+
+	result := effect.Summary{Effects: effect.NewSet(), Chains: make(map[effect.Kind][]string)}
+
+	for k := range summary.Effects {
+		if k != effect.Termination {
+			result.Effects[k] = struct{}{}
+			result.Chains[k] = summary.Chains[k]
+		}
+	}
+
+	return result
+}
+
+// initialized returns the names of the variables that the spec's value at
+// index i initializes: just the one at the same index, or all of them when
+// the value is a single call returning multiple results.
+func initialized(spec *ast.ValueSpec, i int) []string {
+	if len(spec.Names) == len(spec.Values) {
+		return []string{spec.Names[i].Name}
+	}
+
+	return names(spec.Names)
+}
+
+func names(idents []*ast.Ident) []string {
+	var result []string
+
+	for _, ident := range idents {
+		result = append(result, ident.Name)
+	}
+
+	return result
+}
+
+// packageVariable returns the package-level variable at the root of expr, like
+// registry in `registry[name] = h` or http.DefaultClient in
+// `http.DefaultClient.Timeout = d`.
+func packageVariable(expr ast.Expr, info *types.Info) ast.Expr {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if v, ok := info.ObjectOf(e).(*types.Var); ok && isPackageLevel(v) {
+			return e
+		}
+
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			if _, isPackage := info.ObjectOf(ident).(*types.PkgName); isPackage {
+				if packageVariable(e.Sel, info) == nil {
+					return nil
+				}
+
+				return e
+			}
+		}
+
+		return packageVariable(e.X, info)
+
+	case *ast.IndexExpr:
+		return packageVariable(e.X, info)
+
+	case *ast.StarExpr:
+		return packageVariable(e.X, info)
+	}
+
+	return nil
+}
+
+func isPackageLevel(v *types.Var) bool {
+	return !v.IsField() && v.Pkg() != nil && v.Parent() == v.Pkg().Scope()
+}
+
+func isInitFunc(decl *ast.FuncDecl) bool {
+	return decl.Recv == nil && decl.Name.Name == "init" && decl.Body != nil
+}
+
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	if obj := typeutil.Callee(info, call); obj != nil {
+		return effect.ObjectName(obj)
+	}
+
+	return types.ExprString(call.Fun)
+}
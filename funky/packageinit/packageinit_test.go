@@ -0,0 +1,92 @@
+package packageinit
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableEffect struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableEffect{
+		{"testdata/startup/handlers.go:8:2", `init function modifies package variable "handlers"`},
+		{"testdata/startup/main.go:11:14", `initializer of "client" calls example.com/startup.newClient, which has effects: filesystem (via example.com/startup.newClient → os.Stat)`},
+		{"testdata/startup/main.go:15:16", `initializer of "home, ok" calls example.com/startup.lookup, which has effects: environment (via example.com/startup.lookup → os.LookupEnv)`},
+		{"testdata/startup/main.go:30:2", `initializer of "timeout" modifies package variable "count"`},
+		{"testdata/startup/main.go:35:2", `init function modifies package variable "http.DefaultClient"`},
+		{"testdata/startup/main.go:36:2", `init function modifies package variable "count"`},
+		{"testdata/startup/patterns.go:10:15", `initializer of "checked" calls example.com/startup.compile, which has effects: termination (via example.com/startup.compile → panic)`},
+		{"testdata/startup/patterns.go:12:13", `initializer of "shell" calls example.com/startup.mustGetenv, which has effects: environment (via example.com/startup.mustGetenv → os.Getenv)`},
+		{"testdata/startup/config/config.go:8:12", `initializer of "Home" calls os.Getenv, which has effects: environment`},
+		{"testdata/startup/config/config.go:19:2", `init function modifies package variable "started"`},
+		{"testdata/startup/config/config.go:19:12", `init function calls time.Now, which has effects: time`},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "startup")
+	catalog := effect.DefaultCatalog()
+	g := effect.BuildGraph(pkgs, catalog)
+
+	var effects []Effect
+	for _, pkg := range pkgs {
+		effects = append(effects, FindInFiles(pkg.Syntax, pkg.TypesInfo, catalog, g)...)
+	}
+
+	if len(effects) != len(expected) {
+		for _, e := range effects {
+			t.Log(fixture.RelativeLocation(t, e.Location(fset)), e.Message(fset))
+		}
+
+		t.Fatalf("expected %d effects, but found %d", len(expected), len(effects))
+	}
+
+	for i, e := range effects {
+		actual := testableEffect{
+			location: fixture.RelativeLocation(t, e.Location(fset)),
+			message:  e.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	expected := []Step{
+		{Package: "example.com/startup/config", Kind: "var", Name: "Home", Position: "config.go:8", Effects: []effect.Kind{effect.Environment}},
+		{Package: "example.com/startup/config", Kind: "var", Name: "retries", Position: "config.go:12"},
+		{Package: "example.com/startup/config", Kind: "init", Name: "init", Position: "config.go:18", Effects: []effect.Kind{effect.Time}, Writes: []string{"started"}},
+		{Package: "example.com/startup", Kind: "var", Name: "handlers", Position: "handlers.go:5"},
+		{Package: "example.com/startup", Kind: "var", Name: "client", Position: "main.go:11", Effects: []effect.Kind{effect.Filesystem}},
+		{Package: "example.com/startup", Kind: "var", Name: "home, ok", Position: "main.go:15", Effects: []effect.Kind{effect.Environment}},
+		{Package: "example.com/startup", Kind: "var", Name: "timeout", Position: "main.go:29", Writes: []string{"count"}},
+		{Package: "example.com/startup", Kind: "var", Name: "name", Position: "patterns.go:8"},
+		{Package: "example.com/startup", Kind: "var", Name: "checked", Position: "patterns.go:10", Effects: []effect.Kind{effect.Termination}},
+		{Package: "example.com/startup", Kind: "var", Name: "shell", Position: "patterns.go:12", Effects: []effect.Kind{effect.Environment}},
+		{Package: "example.com/startup", Kind: "init", Name: "init", Position: "handlers.go:7", Writes: []string{"handlers"}},
+		{Package: "example.com/startup", Kind: "init", Name: "init", Position: "main.go:34", Writes: []string{"count", "http.DefaultClient"}},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "startup")
+	catalog := effect.DefaultCatalog()
+
+	steps := Order(pkgs, catalog, effect.BuildGraph(pkgs, catalog))
+
+	if !reflect.DeepEqual(steps, expected) {
+		for _, s := range steps {
+			t.Logf("%#v", s)
+		}
+
+		t.Fatalf("expected steps %+v, but found %+v", expected, steps)
+	}
+}
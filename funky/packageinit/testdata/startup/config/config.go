@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+var Home = os.Getenv("HOME")
+
+var started time.Time
+
+var retries = defaultRetries()
+
+func defaultRetries() int {
+	return 3
+}
+
+func init() {
+	started = time.Now()
+}
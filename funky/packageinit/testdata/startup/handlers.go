@@ -0,0 +1,11 @@
+package main
+
+import "fmt"
+
+var handlers = map[string]func(){}
+
+func init() {
+	handlers["hello"] = func() {
+		fmt.Println("hello")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"example.com/startup/config"
+)
+
+var client = newClient()
+
+var count int
+
+var home, ok = lookup()
+
+func newClient() *http.Client {
+	if _, err := os.Stat(config.Home); err != nil {
+		return nil
+	}
+
+	return &http.Client{}
+}
+
+func lookup() (string, bool) {
+	return os.LookupEnv("HOME")
+}
+
+var timeout = func() time.Duration {
+	count++
+	return time.Second
+}()
+
+func init() {
+	http.DefaultClient.Timeout = timeout
+	count = len(handlers)
+}
+
+func main() {
+	count++
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"regexp"
+)
+
+var name = mustCompile("^[a-z]+$")
+
+var checked = compile("^[0-9]+$")
+
+var shell = mustGetenv("SHELL")
+
+func mustCompile(expr string) *regexp.Regexp {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return re
+}
+
+func compile(expr string) *regexp.Regexp {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return re
+}
+
+func mustGetenv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		panic("missing " + key)
+	}
+
+	return value
+}
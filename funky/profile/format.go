@@ -0,0 +1,100 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/luhring/funky/funky/effect"
+)
+
+// Format is a way of writing out a list of profiles.
+type Format string
+
+const (
+	Table    Format = "table"
+	Markdown Format = "markdown"
+	JSON     Format = "json"
+)
+
+// Write writes the profiles to w in the given format.
+func Write(w io.Writer, profiles []Profile, f Format) error {
+	switch f {
+	case Table:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FUNCTION\tEFFECTS")
+
+		for _, p := range profiles {
+			fmt.Fprintf(tw, "%s\t%s\n", p.Name, Describe(p))
+		}
+
+		return tw.Flush()
+
+	case Markdown:
+		fmt.Fprintln(w, "| Function | Effects |")
+		fmt.Fprintln(w, "| --- | --- |")
+
+		for _, p := range profiles {
+			fmt.Fprintf(w, "| `%s` | %s |\n", p.Name, Describe(p))
+		}
+
+		return nil
+
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		// An empty list should be written as [] rather than null.
+		if profiles == nil {
+			profiles = []Profile{}
+		}
+
+		return encoder.Encode(profiles)
+	}
+
+	return fmt.Errorf("unknown format %q (expected %q, %q or %q)", f, Table, Markdown, JSON)
+}
+
+// Describe summarizes what the function does beyond returning values, e.g.
+// "filesystem, mutates parameter s", or "pure".
+func Describe(p Profile) string {
+	if p.Pure {
+		return "pure"
+	}
+
+	var parts []string
+
+	for _, k := range p.Effects {
+		// Termination is described below, along with whether the function
+		// panics itself.
+		if k != effect.Termination {
+			parts = append(parts, string(k))
+		}
+	}
+
+	if p.Nondeterministic {
+		parts = append(parts, "nondeterministic")
+	}
+
+	for _, name := range p.MutatesParameters {
+		parts = append(parts, fmt.Sprintf("mutates parameter %s", name))
+	}
+
+	for _, name := range p.ReadsGlobals {
+		parts = append(parts, fmt.Sprintf("reads global %s", name))
+	}
+
+	for _, name := range p.WritesGlobals {
+		parts = append(parts, fmt.Sprintf("writes global %s", name))
+	}
+
+	if p.Panics {
+		parts = append(parts, "panics")
+	} else if p.MayTerminate {
+		parts = append(parts, "may terminate")
+	}
+
+	return strings.Join(parts, ", ")
+}
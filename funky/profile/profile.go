@@ -0,0 +1,305 @@
+package profile
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/effect"
+	"golang.org/x/tools/go/packages"
+)
+
+// Profile describes what a function does beyond computing its return values.
+type Profile struct {
+	// Name is the function's full name, e.g. "example.com/store.Open" or
+	// "(*example.com/store.Store).Save".
+	Name     string `json:"name"`
+	Package  string `json:"package"`
+	Position string `json:"position"`
+	Exported bool   `json:"exported"`
+
+	// Effects lists the kinds of effect the function has, directly or through
+	// the functions it calls.
+	Effects []effect.Kind `json:"effects,omitempty"`
+
+	// Nondeterministic is true when any of the effects can make the function's
+	// result differ between calls with the same inputs.
+	Nondeterministic bool `json:"nondeterministic"`
+
+	// MutatesParameters lists the parameters (including the receiver) whose
+	// referenced values the function writes to, so the caller can see the change.
+	MutatesParameters []string `json:"mutatesParameters,omitempty"`
+
+	// ReadsGlobals and WritesGlobals list the package-level variables the
+	// function's own body reads and writes.
+	ReadsGlobals  []string `json:"readsGlobals,omitempty"`
+	WritesGlobals []string `json:"writesGlobals,omitempty"`
+
+	// Panics is true when the function's body calls panic.
+	Panics bool `json:"panics"`
+
+	// MayTerminate is true when the function, or any function it calls,
+	// panics, exits the program or ends the calling goroutine, so a call to it
+	// might not return.
+	MayTerminate bool `json:"mayTerminate"`
+
+	// Pure is true when the function does none of the above, so it does
+	// nothing beyond computing its return values from its parameters.
+	Pure bool `json:"pure"`
+
+	decl *ast.FuncDecl
+}
+
+// Contains reports whether pos is within the function's declaration.
+func (p Profile) Contains(pos token.Pos) bool {
+	return p.decl != nil && pos >= p.decl.Pos() && pos < p.decl.End()
+}
+
+// OfPackages profiles every function and method declared in the packages, in
+// order of package path and then position.
+func OfPackages(pkgs []*packages.Package, g *effect.Graph) []Profile {
+	sorted := append([]*packages.Package(nil), pkgs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PkgPath < sorted[j].PkgPath
+	})
+
+	var result []Profile
+
+	for _, pkg := range sorted {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				funcDecl, ok := decl.(*ast.FuncDecl)
+				if !ok || funcDecl.Body == nil {
+					continue
+				}
+
+				fn, ok := pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+
+				result = append(result, of(funcDecl, fn, pkg, g))
+			}
+		}
+	}
+
+	return result
+}
+
+func of(decl *ast.FuncDecl, fn *types.Func, pkg *packages.Package, g *effect.Graph) Profile {
+	effects := g.OfFunc(fn).Effects
+	reads, writes := globals(decl.Body, pkg.TypesInfo)
+	mutated := mutatedParameters(decl, pkg.TypesInfo)
+	panics := callsPanic(decl.Body, pkg.TypesInfo)
+
+	return Profile{
+		Name:              effect.ObjectName(fn),
+		Package:           pkg.PkgPath,
+		Position:          pkg.Fset.Position(decl.Pos()).String(),
+		Exported:          fn.Exported() && exportedReceiver(fn),
+		Effects:           effects.Kinds(),
+		Nondeterministic:  effects.HasAny(effect.Nondeterministic...),
+		MutatesParameters: mutated,
+		ReadsGlobals:      reads,
+		WritesGlobals:     writes,
+		Panics:            panics,
+		MayTerminate:      panics || effects.Has(effect.Termination),
+		Pure:              len(effects) == 0 && len(mutated) == 0 && len(reads) == 0 && len(writes) == 0 && !panics,
+		decl:              decl,
+	}
+}
+
+// exportedReceiver reports whether a method's receiver type is exported, so
+// that the method can be called from other packages.
+func exportedReceiver(fn *types.Func) bool {
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return true
+	}
+
+	t := recv.Type()
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	return !ok || named.Obj().Exported()
+}
+
+// mutatedParameters returns the parameters whose referenced values are written
+// to, e.g. `p.count++` for a pointer p, or `s[0] = x` for a slice s.
+func mutatedParameters(decl *ast.FuncDecl, info *types.Info) []string {
+	params := make(map[types.Object]struct{})
+
+	for _, fields := range []*ast.FieldList{decl.Recv, decl.Type.Params} {
+		if fields == nil {
+			continue
+		}
+
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if obj := info.Defs[name]; obj != nil {
+					params[obj] = struct{}{}
+				}
+			}
+		}
+	}
+
+	var result []string
+
+	for _, target := range writeTargets(decl.Body) {
+		ident := funkyAST.RootIdentFromExpr(target)
+		if ident == nil {
+			continue
+		}
+
+		if _, isParam := params[info.ObjectOf(ident)]; isParam && throughReference(target, info) {
+			result = appendUnique(result, ident.Name)
+		}
+	}
+
+	return result
+}
+
+// throughReference reports whether writing to expr changes a value that's
+// shared with the caller, rather than the function's own copy.
+func throughReference(expr ast.Expr, info *types.Info) bool {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return true
+
+	case *ast.SelectorExpr:
+		if _, ok := typeOf(e.X, info).(*types.Pointer); ok {
+			return true
+		}
+
+		return throughReference(e.X, info)
+
+	case *ast.IndexExpr:
+		switch typeOf(e.X, info).(type) {
+		case *types.Slice, *types.Map, *types.Pointer:
+			return true
+		}
+
+		return throughReference(e.X, info)
+
+	case *ast.ParenExpr:
+		return throughReference(e.X, info)
+	}
+
+	return false
+}
+
+func typeOf(expr ast.Expr, info *types.Info) types.Type {
+	t := info.TypeOf(expr)
+	if t == nil {
+		return nil
+	}
+
+	return t.Underlying()
+}
+
+// globals returns the package-level variables that body reads and writes.
+func globals(body *ast.BlockStmt, info *types.Info) ([]string, []string) {
+	written := make(map[*ast.Ident]struct{})
+
+	var writes []string
+
+	for _, target := range writeTargets(body) {
+		ident := funkyAST.RootIdentFromExpr(target)
+		if v := packageVar(ident, info); v != nil {
+			written[ident] = struct{}{}
+			writes = appendUnique(writes, effect.ObjectName(v))
+		}
+	}
+
+	var reads []string
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		if _, isWrite := written[ident]; isWrite {
+			return true
+		}
+
+		if v := packageVar(ident, info); v != nil {
+			reads = appendUnique(reads, effect.ObjectName(v))
+		}
+
+		return true
+	})
+
+	return reads, writes
+}
+
+func packageVar(ident *ast.Ident, info *types.Info) *types.Var {
+	if ident == nil {
+		return nil
+	}
+
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+
+	return v
+}
+
+// writeTargets returns the expressions written to by assignments and
+// increments in body, including in function literals.
+func writeTargets(body *ast.BlockStmt) []ast.Expr {
+	var result []ast.Expr
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				result = append(result, n.Lhs...)
+			}
+
+		case *ast.IncDecStmt:
+			result = append(result, n.X)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+func callsPanic(body *ast.BlockStmt, info *types.Info) bool {
+	found := false
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok {
+			if ident := funkyAST.IdentFromExpr(ast.Unparen(call.Fun)); ident != nil {
+				if builtin, ok := info.Uses[ident].(*types.Builtin); ok && builtin.Name() == "panic" {
+					found = true
+				}
+			}
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
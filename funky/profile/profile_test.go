@@ -0,0 +1,39 @@
+package profile
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestWrite(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "shop")
+	profiles := OfPackages(pkgs, effect.BuildGraph(pkgs, effect.DefaultCatalog()))
+
+	expected := "| Function | Effects |\n" +
+		"| --- | --- |\n" +
+		"| `example.com/shop.Total` | pure |\n" +
+		"| `(*example.com/shop.Cart).Add` | mutates parameter c |\n" +
+		"| `(example.com/shop.Cart).Count` | pure |\n" +
+		"| `example.com/shop.Checkout` | stdout, time, nondeterministic, writes global example.com/shop.orders |\n" +
+		"| `example.com/shop.Orders` | reads global example.com/shop.orders |\n" +
+		"| `example.com/shop.Sort` | mutates parameter items |\n" +
+		"| `example.com/shop.MustPositive` | panics |\n" +
+		"| `example.com/shop.Abs` | may terminate |\n" +
+		"| `example.com/shop.receipt` | stdout, time, nondeterministic |\n"
+
+	var buf bytes.Buffer
+
+	err := Write(&buf, profiles, Markdown)
+	if err != nil {
+		t.Fatalf("unable to write profiles: %v", err)
+	}
+
+	if actual := buf.String(); actual != expected {
+		t.Errorf("expected:\n%s\nbut found:\n%s", expected, actual)
+	}
+}
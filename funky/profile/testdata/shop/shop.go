@@ -0,0 +1,72 @@
+package shop
+
+import (
+	"fmt"
+	"time"
+)
+
+var orders int
+
+type Cart struct {
+	Items []string
+	total int
+}
+
+func Total(prices []int) int {
+	sum := 0
+	for _, p := range prices {
+		sum += p
+	}
+
+	return sum
+}
+
+func (c *Cart) Add(item string) {
+	c.Items = append(c.Items, item)
+}
+
+func (c Cart) Count() int {
+	c.total = len(c.Items)
+	return c.total
+}
+
+func Checkout(c Cart) string {
+	orders++
+	return receipt(c)
+}
+
+func Orders() int {
+	return orders
+}
+
+func Sort(items []string) {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if items[j] < items[i] {
+				items[i], items[j] = items[j], items[i]
+			}
+		}
+	}
+}
+
+func MustPositive(n int) int {
+	if n < 0 {
+		panic("negative")
+	}
+
+	return n
+}
+
+func Abs(n int) int {
+	if n < 0 {
+		return MustPositive(-n)
+	}
+
+	return MustPositive(n)
+}
+
+func receipt(c Cart) string {
+	s := fmt.Sprintf("%d items at %s", len(c.Items), time.Now())
+	fmt.Println(s)
+	return s
+}
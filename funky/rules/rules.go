@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/luhring/funky/funky/accumulator"
+	"github.com/luhring/funky/funky/alias"
+	"github.com/luhring/funky/funky/boundary"
+	"github.com/luhring/funky/funky/callback"
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/construction"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/escape"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/immutable"
+	"github.com/luhring/funky/funky/initialization"
+	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/nondeterminism"
+	"github.com/luhring/funky/funky/packageinit"
+	"github.com/luhring/funky/funky/shadow"
+	"github.com/luhring/funky/funky/termination"
+	"golang.org/x/tools/go/packages"
+)
+
+// FindingsInPackages runs every rule against each of the packages, along with
+// the rules that follow calls from one package into another.
+func FindingsInPackages(pkgs []*packages.Package, c config.Config) []finding.Finding {
+	var findings []finding.Finding
+
+	catalog := config.Catalog(c)
+
+	// Building the call graph is the expensive part of the analysis, so it's
+	// skipped when none of the rules that need it are in use.
+	var graph *effect.Graph
+	if needsGraph(c) {
+		graph = effect.BuildGraph(pkgs, catalog)
+	}
+
+	facts := mutation.NewWriteFacts(pkgs)
+
+	for _, pkg := range pkgs {
+		findings = append(findings, findingsInFiles(pkg.Syntax, pkg.Types, pkg.TypesInfo, c, catalog, graph, facts)...)
+	}
+
+	findings = append(findings, boundary.Findings(boundary.FindInPackages(pkgs, c, catalog, graph))...)
+	findings = append(findings, immutable.Findings(immutable.FindInPackages(pkgs, c))...)
+
+	return withoutAllowed(finding.Consolidate(findings), c)
+}
+
+// needsGraph reports whether any of the rules that follow the call graph are
+// in use.
+func needsGraph(c config.Config) bool {
+	for _, t := range []finding.Type{effect.Type, callback.Type, packageinit.Type} {
+		if config.RuleLevel(c, t) != config.Allow {
+			return true
+		}
+	}
+
+	return len(c.Boundaries.Core) > 0 && config.RuleLevel(c, boundary.Type) != config.Allow
+}
+
+// FindingsInFiles runs every rule against the files of a single package. Rules
+// that depend on type information are skipped when info is nil.
+func FindingsInFiles(files []*ast.File, pkg *types.Package, info *types.Info, c config.Config) []finding.Finding {
+	findings := findingsInFiles(files, pkg, info, c, config.Catalog(c), nil, nil)
+
+	return withoutAllowed(finding.Consolidate(findings), c)
+}
+
+// findingsInFiles returns every rule's findings in the files of a single
+// package, leaving consolidation and filtering to its callers, which do both
+// once across all of the packages.
+func findingsInFiles(files []*ast.File, pkg *types.Package, info *types.Info, c config.Config, catalog effect.Catalog, graph *effect.Graph, facts *mutation.WriteFacts) []finding.Finding {
+	var findings []finding.Finding
+
+	findings = append(findings, mutation.Findings(mutation.WithoutAllowed(mutation.FindInFiles(files), c))...)
+	findings = append(findings, mutation.AppendAliasFindings(mutation.FindAppendAliasesInFiles(files))...)
+	findings = append(findings, mutation.MethodMutationFindings(mutation.FindMethodMutationsInFiles(files, info, c, facts))...)
+	findings = append(findings, mutation.AddressTakenFindings(mutation.FindAddressesTakenInFiles(files, info, facts))...)
+	findings = append(findings, initialization.Findings(initialization.FindInFiles(files))...)
+	findings = append(findings, accumulator.Findings(accumulator.FindInFiles(files, c))...)
+	findings = append(findings, construction.Findings(construction.FindInFiles(files, info))...)
+	findings = append(findings, shadow.Findings(shadow.FindInFiles(files))...)
+	findings = append(findings, nondeterminism.Findings(nondeterminism.FindInFiles(files, info, catalog))...)
+	findings = append(findings, effect.Findings(effect.FindInFiles(files, pkg, info, catalog, graph))...)
+	findings = append(findings, callback.Findings(callback.FindInFiles(files, info, c, catalog, graph))...)
+	findings = append(findings, packageinit.Findings(packageinit.FindInFiles(files, info, catalog, graph))...)
+	findings = append(findings, termination.Findings(termination.FindInFiles(files, info, catalog))...)
+	findings = append(findings, alias.Findings(alias.FindInFiles(files, info))...)
+	findings = append(findings, escape.Findings(escape.FindInFiles(files, info))...)
+
+	return findings
+}
+
+// Enforced returns the findings whose rules are configured to be enforced.
+func Enforced(findings []finding.Finding, c config.Config) []finding.Finding {
+	var result []finding.Finding
+
+	for _, f := range findings {
+		if config.RuleLevel(c, f.Type()) == config.Enforce {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+func withoutAllowed(findings []finding.Finding, c config.Config) []finding.Finding {
+	var result []finding.Finding
+
+	for _, f := range findings {
+		if config.RuleLevel(c, f.Type()) != config.Allow {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindingsInFiles_subsumedOnlyByType(t *testing.T) {
+	expected := []string{
+		`testdata/subsumed/main.go:9:2: construction-by-mutation: "s" is constructed by 2 field writes after its declaration; use a composite literal instead`,
+		`testdata/subsumed/main.go:11:2: aliased-state: NewStore stores parameter "items" in field items without copying it, so the caller can still modify the slice; store a copy instead`,
+	}
+
+	fset := token.NewFileSet()
+	file, info := fixture.TypedFile(t, fset, "subsumed/main.go")
+
+	findings := FindingsInFiles([]*ast.File{file}, nil, info, config.Default())
+
+	if len(findings) != len(expected) {
+		for _, f := range findings {
+			t.Log(finding.Report(f, fset))
+		}
+
+		t.Fatalf("expected %d findings, but found %d", len(expected), len(findings))
+	}
+
+	for i, f := range findings {
+		if actual := finding.Report(f, fset); actual != expected[i] {
+			t.Errorf("expected %q, but found %q", expected[i], actual)
+		}
+	}
+}
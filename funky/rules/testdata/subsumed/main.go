@@ -0,0 +1,15 @@
+package main
+
+type Store struct {
+	name  string
+	items []string
+}
+
+func NewStore(name string, items []string) *Store {
+	s := &Store{}
+	s.name = name
+	s.items = items // construction-by-mutation and aliased-state
+	return s
+}
+
+func main() {}
@@ -4,7 +4,46 @@ import (
 	"go/ast"
 )
 
-type declsMap map[string]*ast.Ident
+// Kind describes what introduced a declaration into scope.
+type Kind int
+
+const (
+	Variable Kind = iota
+	Parameter
+	Result
+	Receiver
+	Function
+	TypeParameter
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Parameter:
+		return "parameter"
+	case Result:
+		return "named result"
+	case Receiver:
+		return "receiver"
+	case Function:
+		return "function"
+	case TypeParameter:
+		return "type parameter"
+	}
+
+	return "variable"
+}
+
+// Declaration is an identifier declared in a scope, along with what declared it.
+type Declaration struct {
+	Ident *ast.Ident
+	Kind  Kind
+
+	// Index is the position of a parameter or named result within its
+	// function's parameter or result list, starting at 0.
+	Index int
+}
+
+type declsMap map[string]Declaration
 
 type declarationSet struct {
 	decls declsMap
@@ -26,11 +65,11 @@ func (s declarationSet) copy() declarationSet {
 	}
 }
 
-func (s declarationSet) idents() []*ast.Ident {
-	var result []*ast.Ident
+func (s declarationSet) declarations() []Declaration {
+	var result []Declaration
 
-	for _, ident := range s.decls {
-		result = append(result, ident)
+	for _, decl := range s.decls {
+		result = append(result, decl)
 	}
 
 	return result
@@ -45,19 +84,26 @@ func (s declarationSet) contains(i *ast.Ident) bool {
 	return contains
 }
 
-func (s declarationSet) lookup(name string) *ast.Ident {
-	if ident, ok := s.decls[name]; ok {
-		return ident
+func (s declarationSet) lookup(name string) (Declaration, bool) {
+	decl, ok := s.decls[name]
+	return decl, ok
+}
+
+func add(s declarationSet, kind Kind, identities ...*ast.Ident) declarationSet {
+	var decls []Declaration
+
+	for _, identity := range identities {
+		decls = append(decls, Declaration{Ident: identity, Kind: kind})
 	}
 
-	return nil
+	return addDeclarations(s, decls...)
 }
 
-func add(s declarationSet, identities ...*ast.Ident) declarationSet {
+func addDeclarations(s declarationSet, decls ...Declaration) declarationSet {
 	result := s.copy()
 
-	for _, identity := range identities {
-		result.decls[identity.Name] = identity
+	for _, decl := range decls {
+		result.decls[decl.Ident.Name] = decl
 	}
 
 	return result
@@ -3,12 +3,13 @@ package scope
 import (
 	"go/ast"
 	"path"
+	"strconv"
 )
 
 type Scope struct {
 	current declarationSet
 	outer   declarationSet
-	imports []string
+	imports []*ast.ImportSpec
 }
 
 func New() Scope {
@@ -26,24 +27,30 @@ func Copy(s Scope) Scope {
 }
 
 func Append(s Scope, identities ...*ast.Ident) Scope {
+	return AppendOfKind(s, Variable, identities...)
+}
+
+func AppendOfKind(s Scope, kind Kind, identities ...*ast.Ident) Scope {
 	return Scope{
-		current: add(s.current, identities...),
+		current: add(s.current, kind, identities...),
 		outer:   s.outer.copy(),
 		imports: s.imports,
 	}
 }
 
-func WithImports(s Scope, imports []*ast.ImportSpec) Scope {
-	var imported []string
-
-	for _, spec := range imports {
-		imported = append(imported, importName(spec))
+func AppendDeclarations(s Scope, decls ...Declaration) Scope {
+	return Scope{
+		current: addDeclarations(s.current, decls...),
+		outer:   s.outer.copy(),
+		imports: s.imports,
 	}
+}
 
+func WithImports(s Scope, imports []*ast.ImportSpec) Scope {
 	return Scope{
 		current: s.current.copy(),
 		outer:   s.outer.copy(),
-		imports: imported,
+		imports: imports,
 	}
 }
 
@@ -52,13 +59,18 @@ func importName(spec *ast.ImportSpec) string {
 		return spec.Name.Name
 	}
 
-	return path.Base(spec.Path.Value)
+	importPath, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+
+	return path.Base(importPath)
 }
 
 func NewInsideExisting(existing Scope) Scope {
 	// flatten existing scope and set to new scope's outer
 	outer := existing.outer.copy()
-	outer = add(outer, existing.current.idents()...)
+	outer = addDeclarations(outer, existing.current.declarations()...)
 
 	current := newDeclarationSet()
 
@@ -78,7 +90,9 @@ func FromFiles(files []*ast.File) Scope {
 		for _, decl := range file.Decls {
 			switch d := decl.(type) {
 			case *ast.FuncDecl:
-				scope = Append(scope, d.Name)
+				if d.Recv == nil {
+					scope = AppendOfKind(scope, Function, d.Name)
+				}
 			case *ast.GenDecl:
 				for _, spec := range d.Specs {
 					if v, ok := spec.(*ast.ValueSpec); ok {
@@ -98,12 +112,31 @@ func InCurrent(s Scope, identity *ast.Ident) bool {
 	return s.current.contains(identity)
 }
 
+// Lookup finds the innermost declaration of name.
+func Lookup(s Scope, name string) (Declaration, bool) {
+	if decl, ok := s.current.lookup(name); ok {
+		return decl, true
+	}
+
+	return LookupOuter(s, name)
+}
+
+// LookupOuter finds the declaration of name in the scopes enclosing the current one.
+func LookupOuter(s Scope, name string) (Declaration, bool) {
+	return s.outer.lookup(name)
+}
+
 func HasImport(s Scope, name string) bool {
-	for _, scopeImport := range s.imports {
-		if scopeImport == name {
-			return true
+	return LookupImport(s, name) != nil
+}
+
+// LookupImport returns the import spec that brings name into the file's scope, if any.
+func LookupImport(s Scope, name string) *ast.ImportSpec {
+	for _, spec := range s.imports {
+		if importName(spec) == name {
+			return spec
 		}
 	}
 
-	return false
+	return nil
 }
@@ -0,0 +1,204 @@
+package shadow
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/scope"
+)
+
+var Type finding.Type = "shadow"
+
+// Enforce that Shadow implements the Finding type
+var _ finding.Finding = (*Shadow)(nil)
+
+// Shadow describes a `:=` declaration that hides an identifier from an
+// enclosing scope: a variable, parameter, imported package name, or
+// predeclared identifier such as `len` or `error`.
+//
+// An err variable declared by the init statement of an if or switch statement
+// isn't reported when it hides another variable, since that's how an error is
+// scoped to the check that handles it, e.g. `if err := f(); err != nil`.
+type Shadow struct {
+	ident *ast.Ident
+
+	// shadowed is the declaration being hidden. It's nil for predeclared identifiers.
+	shadowed ast.Node
+	kind     string
+}
+
+func (s Shadow) Message(fset *token.FileSet) string {
+	if s.shadowed == nil {
+		return fmt.Sprintf("%q shadows the predeclared %s", s.ident.Name, s.kind)
+	}
+
+	return fmt.Sprintf("%q shadows the %s declared at %s", s.ident.Name, s.kind, s.ShadowedLocation(fset))
+}
+
+func (s Shadow) Type() finding.Type {
+	return Type
+}
+
+func (s Shadow) Node() ast.Node {
+	return s.ident
+}
+
+func (s Shadow) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(s.ident.Pos()).String())
+}
+
+// ShadowedLocation returns the location of the declaration being shadowed, or
+// an empty location for predeclared identifiers.
+func (s Shadow) ShadowedLocation(fset *token.FileSet) finding.Location {
+	if s.shadowed == nil {
+		return ""
+	}
+
+	return finding.Location(fset.Position(s.shadowed.Pos()).String())
+}
+
+func (s Shadow) String() string {
+	return fmt.Sprintf("%q shadowed", s.ident.Name)
+}
+
+func FindInFiles(files []*ast.File) []Shadow {
+	var shadows []Shadow
+
+	packageScope := scope.FromFiles(files)
+
+	// inits holds the init statements of the if and switch statements seen so
+	// far. Those statements are visited before their init statements.
+	inits := make(map[ast.Stmt]struct{})
+
+	for _, file := range files {
+		funkyAST.InspectWithInitialScope(file, func(node ast.Node, s scope.Scope) bool {
+			if node == nil {
+				return false
+			}
+
+			switch stmt := node.(type) {
+			case *ast.IfStmt:
+				if stmt.Init != nil {
+					inits[stmt.Init] = struct{}{}
+				}
+
+			case *ast.SwitchStmt:
+				if stmt.Init != nil {
+					inits[stmt.Init] = struct{}{}
+				}
+
+			case *ast.TypeSwitchStmt:
+				if stmt.Init != nil {
+					inits[stmt.Init] = struct{}{}
+				}
+
+			case *ast.AssignStmt:
+				if stmt.Tok != token.DEFINE {
+					break
+				}
+
+				_, isInit := inits[stmt]
+
+				for _, expr := range stmt.Lhs {
+					ident := funkyAST.IdentFromExpr(expr)
+
+					// Identifiers already declared in the current scope are being
+					// assigned rather than declared.
+					if ident == nil || scope.InCurrent(s, ident) {
+						continue
+					}
+
+					shadow, ok := shadowOf(ident, s, scope.LookupOuter)
+					if !ok || (isInit && isScopedErr(shadow)) {
+						continue
+					}
+
+					shadows = append(shadows, shadow)
+				}
+
+			case *ast.RangeStmt:
+				if stmt.Tok != token.DEFINE {
+					break
+				}
+
+				// The range statement's key and value are declared in a new scope,
+				// so they shadow anything visible at the statement itself.
+				for _, expr := range []ast.Expr{stmt.Key, stmt.Value} {
+					if ident := funkyAST.IdentFromExpr(expr); ident != nil {
+						if shadow, ok := shadowOf(ident, s, scope.Lookup); ok {
+							shadows = append(shadows, shadow)
+						}
+					}
+				}
+			}
+
+			return true
+		}, packageScope)
+	}
+
+	return shadows
+}
+
+func Findings(shadows []Shadow) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, s := range shadows {
+		findings = append(findings, s)
+	}
+
+	return findings
+}
+
+func shadowOf(ident *ast.Ident, s scope.Scope, lookup func(scope.Scope, string) (scope.Declaration, bool)) (Shadow, bool) {
+	if funkyAST.BlankIdentifier(ident) {
+		return Shadow{}, false
+	}
+
+	if decl, ok := lookup(s, ident.Name); ok {
+		return Shadow{
+			ident:    ident,
+			shadowed: decl.Ident,
+			kind:     decl.Kind.String(),
+		}, true
+	}
+
+	if spec := scope.LookupImport(s, ident.Name); spec != nil {
+		return Shadow{
+			ident:    ident,
+			shadowed: spec,
+			kind:     "imported package",
+		}, true
+	}
+
+	if obj := types.Universe.Lookup(ident.Name); obj != nil {
+		return Shadow{
+			ident: ident,
+			kind:  predeclaredKind(obj),
+		}, true
+	}
+
+	return Shadow{}, false
+}
+
+// isScopedErr reports whether the shadow is an err variable hiding another
+// variable.
+func isScopedErr(s Shadow) bool {
+	return s.ident.Name == "err" && s.kind == scope.Variable.String()
+}
+
+func predeclaredKind(obj types.Object) string {
+	switch obj.(type) {
+	case *types.TypeName:
+		return "type"
+	case *types.Builtin:
+		return "function"
+	case *types.Const:
+		return "constant"
+	}
+
+	return "identifier"
+}
@@ -0,0 +1,119 @@
+package shadow
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"testing"
+
+	funkyAST "github.com/luhring/funky/funky/ast"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/mutation"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableShadow struct {
+		location         finding.Location
+		name             string
+		kind             string
+		shadowedLocation finding.Location
+	}
+
+	expected := []testableShadow{
+		{"testdata/shadowing/main.go:13:3", "x", "variable", "testdata/shadowing/main.go:11:2"},
+		{"testdata/shadowing/main.go:21:3", "name", "parameter", "testdata/shadowing/main.go:19:16"},
+		{"testdata/shadowing/main.go:27:5", "err", "named result", "testdata/shadowing/main.go:26:21"},
+		{"testdata/shadowing/main.go:34:2", "count", "variable", "testdata/shadowing/main.go:8:5"},
+		{"testdata/shadowing/main.go:39:2", "fmt", "imported package", "testdata/shadowing/main.go:4:2"},
+		{"testdata/shadowing/main.go:40:2", "str", "imported package", "testdata/shadowing/main.go:5:2"},
+		{"testdata/shadowing/main.go:45:2", "len", "function", ""},
+		{"testdata/shadowing/main.go:46:2", "error", "type", ""},
+		{"testdata/shadowing/main.go:51:9", "items", "parameter", "testdata/shadowing/main.go:50:16"},
+		{"testdata/shadowing/main.go:67:9", "E", "type parameter", "testdata/shadowing/main.go:66:14"},
+		{"testdata/shadowing/main.go:74:3", "T", "type parameter", "testdata/shadowing/main.go:72:12"},
+	}
+
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, "testdata/shadowing", nil, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("unable to load Go source test fixture: %v", err)
+	}
+
+	shadows := FindInFiles(funkyAST.SortedFilesFromPackage(packages["main"]))
+
+	if len(shadows) != len(expected) {
+		t.Fatalf("expected %d shadows, but found %d", len(expected), len(shadows))
+	}
+
+	for i, s := range shadows {
+		actual := testableShadow{
+			location:         s.Location(fset),
+			name:             s.ident.Name,
+			kind:             s.kind,
+			shadowedLocation: s.ShadowedLocation(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
+
+// TestFindInFiles_errReuseFixes makes sure that scoping err the way the
+// err-reuse rule suggests doesn't make the shadow rule report it instead.
+func TestFindInFiles_errReuseFixes(t *testing.T) {
+	const src = `package main
+
+import "os"
+
+func main() {
+	err := os.Chdir("/")
+	if err != nil {
+		return
+	}
+
+	err = os.Chdir("/tmp")
+	if err != nil {
+		print(err)
+	}
+}
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", src, parser.AllErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var edits []analysis.TextEdit
+
+	for _, m := range mutation.FindInFiles([]*ast.File{file}) {
+		for _, fix := range m.SuggestedFixes(fset) {
+			edits = append(edits, fix.TextEdits...)
+		}
+	}
+
+	if len(edits) == 0 {
+		t.Fatal("expected err-reuse to suggest scoping err")
+	}
+
+	// Apply the edits from last to first so that earlier offsets stay valid.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Pos > edits[j].Pos })
+
+	fixed := src
+	for _, edit := range edits {
+		fixed = fixed[:fset.Position(edit.Pos).Offset] + string(edit.NewText) + fixed[fset.Position(edit.End).Offset:]
+	}
+
+	fixedFset := token.NewFileSet()
+	fixedFile, err := parser.ParseFile(fixedFset, "main.go", fixed, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("unable to parse fixed source: %v\n%s", err, fixed)
+	}
+
+	if shadows := FindInFiles([]*ast.File{fixedFile}); len(shadows) != 0 {
+		t.Errorf("expected no shadows in fixed source, but found %d:\n%s", len(shadows), fixed)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	str "strings"
+)
+
+var count int
+
+func variable() {
+	x := 1
+	if true {
+		x := 2 // shadows variable
+		print(x)
+	}
+	print(x)
+}
+
+func parameter(name string) {
+	func() {
+		name := "other" // shadows parameter
+		print(name)
+	}()
+}
+
+func namedResult() (err error) {
+	if err := fmt.Errorf("x"); err != nil { // shadows named result
+		return err
+	}
+	return nil
+}
+
+func packageLevel() {
+	count := 3 // shadows package-level variable
+	print(count)
+}
+
+func imports() {
+	fmt := "f" // shadows imported package
+	str := "s" // shadows imported package (renamed)
+	print(fmt, str)
+}
+
+func predeclared() {
+	len := 1   // shadows predeclared function
+	error := 2 // shadows predeclared type
+	print(len, error)
+}
+
+func rangeLoop(items []string) {
+	for _, items := range items { // shadows parameter
+		print(items)
+	}
+}
+
+func notShadowing() {
+	a := 1
+	a, b := 2, 3 // a is reassigned, b is new
+	print(a, b)
+}
+
+type list[E any] struct {
+	items []E
+}
+
+func (l list[E]) each(f func(E)) {
+	for _, E := range l.items { // shadows type parameter of receiver
+		f(E)
+	}
+}
+
+func first[T any](values []T) T {
+	if len(values) > 0 {
+		T := values[0] // shadows type parameter
+		return T
+	}
+
+	var zero T
+	return zero
+}
+
+func scopedErr() error {
+	err := fmt.Errorf("x")
+	if err != nil {
+		return err
+	}
+
+	if err := fmt.Errorf("y"); err != nil { // not reported: scoped to the check
+		return err
+	}
+
+	switch _, err := fmt.Println(); err { // not reported: scoped to the switch
+	case nil:
+		print("ok")
+	}
+
+	return nil
+}
+
+func main() {}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/luhring/funky/funky/profile"
+)
+
+// Write writes the report to w in the given format.
+func Write(w io.Writer, report Report, f profile.Format) error {
+	switch f {
+	case profile.Table:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "PACKAGE\tFUNCTIONS\tPURE\tMUTATION-FREE\tSCORE")
+
+		for _, pkg := range report.Packages {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", pkg.Path, len(pkg.Functions), pkg.Pure, pkg.MutationFree, score(pkg))
+		}
+
+		fmt.Fprintf(tw, "TOTAL\t\t\t\t%.1f%%\n", report.Score)
+
+		if len(report.Changes) > 0 {
+			fmt.Fprintln(tw)
+			fmt.Fprintln(tw, "FUNCTION\tBEFORE\tAFTER")
+
+			for _, c := range report.Changes {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Function, c.Before, c.After)
+			}
+		}
+
+		return tw.Flush()
+
+	case profile.Markdown:
+		fmt.Fprintln(w, "| Package | Functions | Pure | Mutation-free | Score |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+
+		for _, pkg := range report.Packages {
+			fmt.Fprintf(w, "| `%s` | %d | %d | %d | %s |\n", pkg.Path, len(pkg.Functions), pkg.Pure, pkg.MutationFree, score(pkg))
+		}
+
+		fmt.Fprintf(w, "| **Total** | | | | %.1f%% |\n", report.Score)
+
+		if len(report.Changes) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "| Function | Before | After |")
+			fmt.Fprintln(w, "| --- | --- | --- |")
+
+			for _, c := range report.Changes {
+				fmt.Fprintf(w, "| `%s` | %s | %s |\n", c.Function, c.Before, c.After)
+			}
+		}
+
+		return nil
+
+	case profile.JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	}
+
+	return fmt.Errorf("unknown format %q (expected %q, %q or %q)", f, profile.Table, profile.Markdown, profile.JSON)
+}
+
+// score formats a package's score, along with the change since the baseline.
+func score(pkg Package) string {
+	if pkg.BaselineScore == nil {
+		return fmt.Sprintf("%.1f%%", pkg.Score)
+	}
+
+	return fmt.Sprintf("%.1f%% (%+.1f)", pkg.Score, pkg.Score-*pkg.BaselineScore)
+}
@@ -0,0 +1,239 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/mutation"
+	"github.com/luhring/funky/funky/profile"
+	"golang.org/x/tools/go/packages"
+)
+
+// Report is an inventory of the functions in a set of packages that are pure
+// and mutation-free.
+type Report struct {
+	Packages []Package `json:"packages"`
+
+	// Score is the percentage of all functions that are pure and mutation-free.
+	Score float64 `json:"score"`
+
+	// Changes lists the functions whose status differs from the baseline, when
+	// the report has been compared to one.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Package holds the statistics for a single package.
+type Package struct {
+	Path      string     `json:"path"`
+	Functions []Function `json:"functions"`
+
+	Pure         int `json:"pure"`
+	MutationFree int `json:"mutationFree"`
+
+	// Score is the percentage of the package's functions that are pure and mutation-free.
+	Score float64 `json:"score"`
+
+	// BaselineScore is the package's score in the baseline, if the report has
+	// been compared to one that includes the package.
+	BaselineScore *float64 `json:"baselineScore,omitempty"`
+}
+
+// Function records whether a single function is pure and mutation-free.
+type Function struct {
+	Name string `json:"name"`
+
+	// Pure is true when the function has no effects, doesn't mutate its
+	// parameters, doesn't use package-level variables, and doesn't panic.
+	Pure bool `json:"pure"`
+
+	// MutationFree is true when none of the function's variables are assigned
+	// a new value after they're declared.
+	MutationFree bool `json:"mutationFree"`
+}
+
+// Change describes how a function's status differs from the baseline.
+type Change struct {
+	Function string `json:"function"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+// Compute builds the report for the packages. Mutations whose rules are set to
+// allow don't count against a function.
+func Compute(pkgs []*packages.Package, g *effect.Graph, c config.Config) Report {
+	var mutations []mutation.Mutation
+	for _, pkg := range pkgs {
+		mutations = append(mutations, enforced(mutation.WithoutAllowed(mutation.FindInFiles(pkg.Syntax), c), c)...)
+	}
+
+	var report Report
+
+	total, functional := 0, 0
+
+	for _, profiles := range byPackage(profile.OfPackages(pkgs, g)) {
+		pkg := Package{Path: profiles[0].Package}
+
+		count := 0
+
+		for _, p := range profiles {
+			f := Function{
+				Name:         p.Name,
+				Pure:         p.Pure,
+				MutationFree: !mutatedWithin(mutations, p),
+			}
+
+			if f.Pure {
+				pkg.Pure++
+			}
+
+			if f.MutationFree {
+				pkg.MutationFree++
+			}
+
+			if f.Pure && f.MutationFree {
+				count++
+			}
+
+			pkg.Functions = append(pkg.Functions, f)
+		}
+
+		pkg.Score = percentage(count, len(profiles))
+		total += len(profiles)
+		functional += count
+
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	report.Score = percentage(functional, total)
+
+	return report
+}
+
+// Compare returns the report with the baseline's package scores and the
+// functions whose status has changed since the baseline was computed.
+func Compare(baseline, current Report) Report {
+	before := make(map[string]Function)
+	baselineScores := make(map[string]float64)
+
+	for _, pkg := range baseline.Packages {
+		baselineScores[pkg.Path] = pkg.Score
+
+		for _, f := range pkg.Functions {
+			before[f.Name] = f
+		}
+	}
+
+	result := Report{Score: current.Score}
+	after := make(map[string]struct{})
+
+	for _, pkg := range current.Packages {
+		if score, ok := baselineScores[pkg.Path]; ok {
+			pkg.BaselineScore = &score
+		}
+
+		for _, f := range pkg.Functions {
+			after[f.Name] = struct{}{}
+
+			previous, existed := before[f.Name]
+			switch {
+			case !existed:
+				result.Changes = append(result.Changes, Change{Function: f.Name, Before: "new", After: Status(f)})
+			case Status(previous) != Status(f):
+				result.Changes = append(result.Changes, Change{Function: f.Name, Before: Status(previous), After: Status(f)})
+			}
+		}
+
+		result.Packages = append(result.Packages, pkg)
+	}
+
+	for _, pkg := range baseline.Packages {
+		for _, f := range pkg.Functions {
+			if _, exists := after[f.Name]; !exists {
+				result.Changes = append(result.Changes, Change{Function: f.Name, Before: Status(f), After: "removed"})
+			}
+		}
+	}
+
+	return result
+}
+
+// Status describes a function as "pure and mutation-free", "pure",
+// "mutation-free" or "neither".
+func Status(f Function) string {
+	switch {
+	case f.Pure && f.MutationFree:
+		return "pure and mutation-free"
+	case f.Pure:
+		return "pure"
+	case f.MutationFree:
+		return "mutation-free"
+	}
+
+	return "neither"
+}
+
+// Load reads a report previously written in JSON, to be used as a baseline.
+func Load(path string) (Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		return Report{}, fmt.Errorf("unable to parse baseline %q: %w", path, err)
+	}
+
+	return report, nil
+}
+
+// byPackage splits profiles, which are ordered by package, into one list per package.
+func byPackage(profiles []profile.Profile) [][]profile.Profile {
+	var result [][]profile.Profile
+
+	for i, p := range profiles {
+		if i == 0 || p.Package != profiles[i-1].Package {
+			result = append(result, nil)
+		}
+
+		result[len(result)-1] = append(result[len(result)-1], p)
+	}
+
+	return result
+}
+
+// enforced drops the mutations whose rules are set to allow.
+func enforced(mutations []mutation.Mutation, c config.Config) []mutation.Mutation {
+	var result []mutation.Mutation
+
+	for _, m := range mutations {
+		if config.RuleLevel(c, m.Type()) != config.Allow {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+func mutatedWithin(mutations []mutation.Mutation, p profile.Profile) bool {
+	for _, m := range mutations {
+		if p.Contains(m.Node().Pos()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func percentage(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return float64(n) * 100 / float64(total)
+}
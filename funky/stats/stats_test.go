@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/luhring/funky/funky/config"
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestCompute(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "scores")
+	report := Compute(pkgs, effect.BuildGraph(pkgs, effect.DefaultCatalog()), config.Default())
+
+	expected := Report{
+		Packages: []Package{
+			{
+				Path: "example.com/scores",
+				Functions: []Function{
+					{Name: "example.com/scores.main", Pure: false, MutationFree: true},
+				},
+				Pure:         0,
+				MutationFree: 1,
+				Score:        0,
+			},
+			{
+				Path: "example.com/scores/geometry",
+				Functions: []Function{
+					{Name: "example.com/scores/geometry.Area", Pure: true, MutationFree: true},
+					{Name: "example.com/scores/geometry.Perimeter", Pure: true, MutationFree: false},
+					{Name: "(*example.com/scores/geometry.Rect).Scale", Pure: false, MutationFree: false},
+					{Name: "example.com/scores/geometry.Sum", Pure: true, MutationFree: false},
+				},
+				Pure:         3,
+				MutationFree: 1,
+				Score:        25,
+			},
+		},
+		Score: 20,
+	}
+
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected %+v, but found %+v", expected, report)
+	}
+}
+
+func TestCompute_allowedRules(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "scores")
+
+	c := config.Default()
+	c.Rules = map[string]config.Level{"mutation": config.Allow}
+
+	report := Compute(pkgs, effect.BuildGraph(pkgs, effect.DefaultCatalog()), c)
+
+	// With mutations allowed, only purity counts against a function.
+	geometry := report.Packages[1]
+	if geometry.MutationFree != 4 || geometry.Score != 75 {
+		t.Errorf("expected 4 mutation-free functions and a score of 75, but found %d and %v", geometry.MutationFree, geometry.Score)
+	}
+
+	if report.Score != 60 {
+		t.Errorf("expected a score of 60, but found %v", report.Score)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := Report{
+		Packages: []Package{
+			{
+				Path: "example.com/scores/geometry",
+				Functions: []Function{
+					{Name: "example.com/scores/geometry.Area", Pure: true, MutationFree: true},
+					{Name: "example.com/scores/geometry.Perimeter", Pure: true, MutationFree: false},
+					{Name: "example.com/scores/geometry.Volume", Pure: true, MutationFree: true},
+				},
+				Score: 66.7,
+			},
+		},
+	}
+
+	current := Report{
+		Packages: []Package{
+			{
+				Path: "example.com/scores/geometry",
+				Functions: []Function{
+					{Name: "example.com/scores/geometry.Area", Pure: true, MutationFree: true},
+					{Name: "example.com/scores/geometry.Perimeter", Pure: true, MutationFree: true},
+					{Name: "example.com/scores/geometry.Sum", Pure: false, MutationFree: false},
+				},
+				Score: 66.7,
+			},
+		},
+	}
+
+	expected := []Change{
+		{Function: "example.com/scores/geometry.Perimeter", Before: "pure", After: "pure and mutation-free"},
+		{Function: "example.com/scores/geometry.Sum", Before: "new", After: "neither"},
+		{Function: "example.com/scores/geometry.Volume", Before: "pure and mutation-free", After: "removed"},
+	}
+
+	report := Compare(baseline, current)
+
+	if !reflect.DeepEqual(report.Changes, expected) {
+		t.Errorf("expected changes %+v, but found %+v", expected, report.Changes)
+	}
+
+	if score := report.Packages[0].BaselineScore; score == nil || *score != 66.7 {
+		t.Errorf("expected baseline score 66.7, but found %v", score)
+	}
+}
@@ -0,0 +1,29 @@
+package geometry
+
+func Area(w, h int) int {
+	return w * h
+}
+
+func Perimeter(w, h int) int {
+	p := w + h
+	p = p * 2
+	return p
+}
+
+type Rect struct {
+	W, H int
+}
+
+func (r *Rect) Scale(n int) {
+	r.W *= n
+	r.H *= n
+}
+
+func Sum(areas []int) int {
+	total := 0
+	for _, a := range areas {
+		total += a
+	}
+
+	return total
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/scores/geometry"
+)
+
+func main() {
+	fmt.Println(geometry.Area(2, 3))
+}
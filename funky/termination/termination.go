@@ -0,0 +1,167 @@
+package termination
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+)
+
+var Type finding.Type = "library-termination"
+
+// Enforce that Termination implements the Finding type
+var _ finding.Finding = (*Termination)(nil)
+
+// Termination describes a call in a library package to a function that can
+// end the program or the calling goroutine, like os.Exit, log.Fatal,
+// runtime.Goexit or panic. Whoever calls into the library loses the chance to
+// handle the failure, so libraries should return errors instead and leave the
+// decision to package main.
+type Termination struct {
+	call *ast.CallExpr
+
+	// name is the catalog name of the function being called, e.g. "os.Exit".
+	name string
+}
+
+func (t Termination) Message(_ *token.FileSet) string {
+	return fmt.Sprintf("call to %s may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead", t.name)
+}
+
+func (t Termination) Type() finding.Type {
+	return Type
+}
+
+func (t Termination) Node() ast.Node {
+	return t.call
+}
+
+func (t Termination) Location(fset *token.FileSet) finding.Location {
+	return finding.Location(fset.Position(t.call.Pos()).String())
+}
+
+func (t Termination) String() string {
+	return fmt.Sprintf("termination via %s", t.name)
+}
+
+// FindInFiles reports the calls that the catalog attributes the termination
+// effect to, in files that aren't part of package main. Only direct calls are
+// reported; the effect rule and `funky effects` show which functions may
+// terminate through the functions they call. Panics on the error path of Must
+// helpers, like regexp.MustCompile, aren't reported, since panicking there is
+// what the helpers are for.
+func FindInFiles(files []*ast.File, info *types.Info, c effect.Catalog) []Termination {
+	if info == nil {
+		return nil
+	}
+
+	var result []Termination
+
+	for _, file := range files {
+		if file.Name.Name == "main" {
+			continue
+		}
+
+		mustPanics := mustPanicsInFile(file, info)
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			if _, ok := mustPanics[call]; ok {
+				return true
+			}
+
+			if effects, name := effect.OfCall(call, info, c); effects.Has(effect.Termination) {
+				result = append(result, Termination{call: call, name: name})
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+func Findings(terminations []Termination) []finding.Finding {
+	var findings []finding.Finding
+
+	for _, t := range terminations {
+		findings = append(findings, t)
+	}
+
+	return findings
+}
+
+// mustPanicsInFile finds the calls to panic that Must helpers make when an
+// error isn't nil, as in `if err != nil { panic(err) }`.
+func mustPanicsInFile(file *ast.File, info *types.Info) map[*ast.CallExpr]struct{} {
+	result := make(map[*ast.CallExpr]struct{})
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil || !effect.IsMust(info.Defs[funcDecl.Name]) {
+			continue
+		}
+
+		ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncLit:
+				return false
+
+			case *ast.IfStmt:
+				if isErrCheck(n.Cond, info) {
+					addPanics(n.Body, info, result)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+// addPanics adds the calls to panic in the block to result, leaving out the
+// bodies of function literals.
+func addPanics(block *ast.BlockStmt, info *types.Info, result map[*ast.CallExpr]struct{}) {
+	ast.Inspect(block, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+
+		case *ast.CallExpr:
+			if ident, ok := ast.Unparen(n.Fun).(*ast.Ident); ok && info.Uses[ident] == types.Universe.Lookup("panic") {
+				result[n] = struct{}{}
+			}
+		}
+
+		return true
+	})
+}
+
+// isErrCheck matches `err != nil` and `nil != err` for any expression of type
+// error.
+func isErrCheck(expr ast.Expr, info *types.Info) bool {
+	binary, ok := ast.Unparen(expr).(*ast.BinaryExpr)
+	if !ok || binary.Op != token.NEQ {
+		return false
+	}
+
+	errorType := types.Universe.Lookup("error").Type()
+
+	isNil := func(expr ast.Expr) bool {
+		return info.Types[expr].IsNil()
+	}
+
+	isErr := func(expr ast.Expr) bool {
+		return types.Identical(info.TypeOf(expr), errorType)
+	}
+
+	return (isErr(binary.X) && isNil(binary.Y)) || (isNil(binary.X) && isErr(binary.Y))
+}
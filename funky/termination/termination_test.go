@@ -0,0 +1,54 @@
+package termination
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/luhring/funky/funky/effect"
+	"github.com/luhring/funky/funky/finding"
+	"github.com/luhring/funky/funky/internal/fixture"
+)
+
+func TestFindInFiles(t *testing.T) {
+	type testableTermination struct {
+		location finding.Location
+		message  string
+	}
+
+	expected := []testableTermination{
+		{"testdata/cli/store/store.go:18:3", "call to os.Exit may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+		{"testdata/cli/store/store.go:22:3", "call to log.Fatalf may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+		{"testdata/cli/store/store.go:26:3", "call to (*log.Logger).Fatal may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+		{"testdata/cli/store/store.go:39:2", "call to runtime.Goexit may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+		{"testdata/cli/store/store.go:44:3", "call to panic may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+		{"testdata/cli/store/store.go:50:3", "call to panic may terminate the program or goroutine without giving the caller a chance to handle it; return an error instead"},
+	}
+
+	fset := token.NewFileSet()
+	pkgs := fixture.Packages(t, fset, "cli")
+	catalog := effect.DefaultCatalog()
+
+	var terminations []Termination
+	for _, pkg := range pkgs {
+		terminations = append(terminations, FindInFiles(pkg.Syntax, pkg.TypesInfo, catalog)...)
+	}
+
+	if len(terminations) != len(expected) {
+		for _, term := range terminations {
+			t.Log(fixture.RelativeLocation(t, term.Location(fset)), term.Message(fset))
+		}
+
+		t.Fatalf("expected %d terminations, but found %d", len(expected), len(terminations))
+	}
+
+	for i, term := range terminations {
+		actual := testableTermination{
+			location: fixture.RelativeLocation(t, term.Location(fset)),
+			message:  term.Message(fset),
+		}
+
+		if actual != expected[i] {
+			t.Errorf("expected %+v, but found %+v", expected[i], actual)
+		}
+	}
+}
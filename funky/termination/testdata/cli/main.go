@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"example.com/cli/store"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: cli <key>")
+	}
+
+	if err := store.Open(os.Args[1]); err != nil {
+		os.Exit(1)
+	}
+}
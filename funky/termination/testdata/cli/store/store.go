@@ -0,0 +1,54 @@
+package store
+
+import (
+	"errors"
+	"log"
+	"os"
+	"runtime"
+)
+
+var logger = log.New(os.Stderr, "store: ", 0)
+
+func Open(key string) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+
+	if key == "exit" {
+		os.Exit(2)
+	}
+
+	if key == "fatal" {
+		log.Fatalf("bad key %q", key)
+	}
+
+	if key == "logger" {
+		logger.Fatal("bad key")
+	}
+
+	return nil
+}
+
+func MustOpen(key string) {
+	if err := Open(key); err != nil {
+		panic(err)
+	}
+}
+
+func Stop() {
+	runtime.Goexit()
+}
+
+func Check(key string) {
+	if err := Open(key); err != nil {
+		panic(err)
+	}
+}
+
+func MustNotBeEmpty(key string) string {
+	if key == "" {
+		panic("empty key")
+	}
+
+	return key
+}